@@ -0,0 +1,10 @@
+// Package doctrainergo embeds the default templates and static assets, so
+// the binary works from any directory.
+package doctrainergo
+
+import "embed"
+
+// Assets holds templates/ and static/ as they were at build time
+//
+//go:embed templates static
+var Assets embed.FS
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 
+	"docTrainerGO/internal/assets"
 	"docTrainerGO/internal/chat"
 	"docTrainerGO/internal/cli"
 	"docTrainerGO/internal/config"
@@ -30,6 +31,11 @@ func main() {
 		fmt.Println("Processing PDF from command line...")
 	}
 
+	// Override audience profile if provided
+	if commandLine.HasProfile() {
+		cfg.Profile = commandLine.GetProfile()
+	}
+
 	// Initialize Ollama client if enabled
 	var ollamaClient *chat.OllamaClient
 	if cfg.Ollama.Enabled {
@@ -45,6 +51,25 @@ func main() {
 	fmt.Println("\n✓ Processing complete!")
 	fmt.Println("✓ Documentation generated in:", cfg.Output.Directory)
 
+	// Export a single HTML file for offline reading
+	if commandLine.HasExportHTMLPath() {
+		if err := proc.ExportHTML(commandLine.GetExportHTMLPath()); err != nil {
+			log.Fatalf("Failed to export HTML: %v", err)
+		}
+	}
+
+	// Export an EPUB book for e-readers
+	if commandLine.HasExportEPUBPath() {
+		if err := proc.ExportEPUB(commandLine.GetExportEPUBPath()); err != nil {
+			log.Fatalf("Failed to export EPUB: %v", err)
+		}
+	}
+
+	// Exports exit unless the server was requested too
+	if (commandLine.HasExportHTMLPath() || commandLine.HasExportEPUBPath()) && !commandLine.ShouldServe() {
+		return
+	}
+
 	// Exit if process-only mode
 	if commandLine.ShouldProcessAndExit() {
 		fmt.Println("\nProcess-only mode: exiting without starting server")
@@ -72,7 +97,7 @@ func main() {
 		}
 
 		// Start server
-		srv := server.New(cfg.Server.Port, cfg.Output.Directory, ollamaClient)
+		srv := server.New(cfg.Server.Port, cfg.Output.Directory, assets.New(cfg).Static(), ollamaClient)
 		if err := srv.Start(); err != nil {
 			log.Fatalf("Failed to start server: %v", err)
 		}
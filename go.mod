@@ -4,6 +4,10 @@ go 1.24.1
 
 toolchain go1.24.2
 
-require github.com/ledongthuc/pdf v0.0.0-20250511090121-5959a4027728
-
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	github.com/ledongthuc/pdf v0.0.0-20250511090121-5959a4027728
+	golang.org/x/mod v0.33.0
+	golang.org/x/net v0.50.0
+	golang.org/x/text v0.34.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,114 @@
+package assets
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	doctrainergo "docTrainerGO"
+	"docTrainerGO/internal/config"
+)
+
+// FS serves templates and static assets. Files in the configured directories
+// override the embedded defaults one by one, so a theme only needs the files
+// it changes.
+type FS struct {
+	layers []fs.FS // searched in order; the embedded assets come last
+}
+
+// New layers the directories from the theme configuration over the embedded
+// assets: theme.templates and theme.static first, then theme.directory
+func New(cfg *config.Config) *FS {
+	a := &FS{}
+	if cfg.Theme.Templates != "" {
+		a.layers = append(a.layers, mount{prefix: "templates", fsys: os.DirFS(cfg.Theme.Templates)})
+	}
+	if cfg.Theme.Static != "" {
+		a.layers = append(a.layers, mount{prefix: "static", fsys: os.DirFS(cfg.Theme.Static)})
+	}
+	if cfg.Theme.Directory != "" {
+		a.layers = append(a.layers, os.DirFS(cfg.Theme.Directory))
+	}
+	a.layers = append(a.layers, doctrainergo.Assets)
+	return a
+}
+
+// Open opens a file from the first layer that has it
+func (a *FS) Open(name string) (fs.File, error) {
+	var lastErr error
+	for _, layer := range a.layers {
+		f, err := layer.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return nil, lastErr
+}
+
+// ReadDir lists a directory across all layers, so a theme that overrides
+// one partial still sees the built-in ones
+func (a *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	seen := make(map[string]bool)
+	found := false
+	for _, layer := range a.layers {
+		layerEntries, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, entry := range layerEntries {
+			if !seen[entry.Name()] {
+				seen[entry.Name()] = true
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Static returns the static assets, for serving under /static/
+func (a *FS) Static() fs.FS {
+	static, err := fs.Sub(a, "static")
+	if err != nil {
+		// fs.Sub only fails for invalid directory names
+		panic(err)
+	}
+	return static
+}
+
+// mount exposes a directory under a path prefix, e.g. a templates directory
+// configured on its own as "templates/"
+type mount struct {
+	prefix string
+	fsys   fs.FS
+}
+
+// Open opens a file below the prefix
+func (m mount) Open(name string) (fs.File, error) {
+	if name == m.prefix {
+		return m.fsys.Open(".")
+	}
+	rest, ok := strings.CutPrefix(name, m.prefix+"/")
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return m.fsys.Open(rest)
+}
@@ -104,14 +104,25 @@ func (c *OllamaClient) Ask(prompt string) (string, error) {
 
 // AskWithContext sends a question with document context to the LLM
 func (c *OllamaClient) AskWithContext(question, context string) (string, error) {
-	prompt := fmt.Sprintf(`You are a helpful documentation assistant. Use the following context from the documentation to answer the user's question. If the answer is not in the context, say so.
+	return c.AskWithContextInLanguage(question, context, "")
+}
+
+// AskWithContextInLanguage sends a question with document context to the LLM
+// and asks for the answer in the given language (e.g. "Deutsch")
+func (c *OllamaClient) AskWithContextInLanguage(question, context, language string) (string, error) {
+	languageInstruction := ""
+	if language != "" {
+		languageInstruction = fmt.Sprintf(" Always answer in %s, the language the user is reading the documentation in.", language)
+	}
+
+	prompt := fmt.Sprintf(`You are a helpful documentation assistant. Use the following context from the documentation to answer the user's question. If the answer is not in the context, say so. Section headings show their full path (for example "Configuration › Ollama › Models"); use that path when pointing the user to where an answer comes from. Passages marked [WARNING], [CAUTION] or [IMPORTANT] are critical; always mention them when they apply to the question.%s
 
 Context:
 %s
 
 Question: %s
 
-Answer:`, context, question)
+Answer:`, languageInstruction, context, question)
 
 	return c.Ask(prompt)
 }
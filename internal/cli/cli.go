@@ -12,6 +12,9 @@ type CLI struct {
 	configPath     *string
 	serve          *bool
 	processAndExit *bool
+	profile        *string
+	exportHTML     *string
+	exportEPUB     *string
 	help           *bool
 }
 
@@ -22,6 +25,9 @@ func New() *CLI {
 		configPath:     flag.String("config", "config.yaml", "Path to configuration file"),
 		serve:          flag.Bool("serve", false, "Start web server after processing"),
 		processAndExit: flag.Bool("process", false, "Process document and exit (don't start server)"),
+		profile:        flag.String("profile", "", "Audience profile to build (overrides config)"),
+		exportHTML:     flag.String("export-html", "", "Write the documentation as one self-contained HTML file"),
+		exportEPUB:     flag.String("export-epub", "", "Write the documentation as an EPUB 3 book"),
 		help:           flag.Bool("help", false, "Show help message"),
 	}
 }
@@ -61,6 +67,36 @@ func (c *CLI) HasPDFPath() bool {
 	return *c.pdfPath != ""
 }
 
+// GetProfile returns the audience profile if provided
+func (c *CLI) GetProfile() string {
+	return *c.profile
+}
+
+// HasProfile returns whether an audience profile was provided
+func (c *CLI) HasProfile() bool {
+	return *c.profile != ""
+}
+
+// GetExportHTMLPath returns the path of the single-file HTML export
+func (c *CLI) GetExportHTMLPath() string {
+	return *c.exportHTML
+}
+
+// HasExportHTMLPath returns whether a single-file HTML export was requested
+func (c *CLI) HasExportHTMLPath() bool {
+	return *c.exportHTML != ""
+}
+
+// GetExportEPUBPath returns the path of the EPUB export
+func (c *CLI) GetExportEPUBPath() string {
+	return *c.exportEPUB
+}
+
+// HasExportEPUBPath returns whether an EPUB export was requested
+func (c *CLI) HasExportEPUBPath() bool {
+	return *c.exportEPUB != ""
+}
+
 // showHelp displays usage information
 func (c *CLI) showHelp() {
 	fmt.Println("docTrainerGO - Generate searchable documentation with AI chat from PDF or Markdown files")
@@ -75,6 +111,12 @@ func (c *CLI) showHelp() {
 	fmt.Println("        Path to PDF file to process (overrides config)")
 	fmt.Println("  -process")
 	fmt.Println("        Process document and exit without starting server")
+	fmt.Println("  -profile string")
+	fmt.Println("        Audience profile to build, e.g. admin (overrides config)")
+	fmt.Println("  -export-html string")
+	fmt.Println("        Write one self-contained HTML file for offline reading, then exit")
+	fmt.Println("  -export-epub string")
+	fmt.Println("        Write an EPUB 3 book for e-readers, then exit")
 	fmt.Println("  -serve")
 	fmt.Println("        Start web server after processing")
 	fmt.Println("  -help")
@@ -90,6 +132,15 @@ func (c *CLI) showHelp() {
 	fmt.Println("  # Process only without starting server")
 	fmt.Println("  docTrainerGO -pdf input/document.pdf -process")
 	fmt.Println()
+	fmt.Println("  # Build the admin variant of the markdown docs")
+	fmt.Println("  docTrainerGO -profile admin -process")
+	fmt.Println()
+	fmt.Println("  # Export a single HTML file that opens from disk")
+	fmt.Println("  docTrainerGO -export-html manual.html")
+	fmt.Println()
+	fmt.Println("  # Export an EPUB book")
+	fmt.Println("  docTrainerGO -export-epub manual.epub")
+	fmt.Println()
 	fmt.Println("Configuration:")
 	fmt.Println("  Edit config.yaml to configure:")
 	fmt.Println("  - Input type (pdf or markdown)")
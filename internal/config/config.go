@@ -18,11 +18,39 @@ type Config struct {
 		Directory    string   `yaml:"directory"`
 		AutoDiscover bool     `yaml:"auto_discover"`
 		Files        []string `yaml:"files"`
+		Locales      []Locale `yaml:"locales"`
 	} `yaml:"markdown"`
-	Output struct {
+	DOCX struct {
+		Path string `yaml:"path"` // A .docx file or a directory of them
+	} `yaml:"docx"`
+	HTML struct {
 		Directory string `yaml:"directory"`
-		Title     string `yaml:"title"`
+		Selector  string `yaml:"selector"` // CSS selector of the main content region
+	} `yaml:"html"`
+	GoDoc struct {
+		Directory string `yaml:"directory"` // Go module root (the directory holding go.mod)
+	} `yaml:"godoc"`
+	OpenAPI struct {
+		Path string `yaml:"path"` // OpenAPI 3 spec in YAML or JSON
+	} `yaml:"openapi"`
+	EPUB struct {
+		Path string `yaml:"path"` // EPUB 2 or 3 book
+	} `yaml:"epub"`
+	Man struct {
+		Path string `yaml:"path"` // man page or directory of man pages
+	} `yaml:"man"`
+	Notebook struct {
+		Path string `yaml:"path"` // .ipynb file or directory of notebooks
+	} `yaml:"notebook"`
+	Output struct {
+		Directory   string `yaml:"directory"`
+		Title       string `yaml:"title"`
+		Author      string `yaml:"author"`       // Author recorded in exported books
+		StaticPages bool   `yaml:"static_pages"` // Also render a static HTML page per section
+		PageLevel   int    `yaml:"page_level"`   // Deepest heading level with its own static page (0 = every section)
+		BaseURL     string `yaml:"base_url"`     // Public URL of the output directory, for canonical links
 	} `yaml:"output"`
+	Theme  Theme `yaml:"theme"`
 	Server struct {
 		Port string `yaml:"port"`
 		Host string `yaml:"host"`
@@ -32,6 +60,63 @@ type Config struct {
 		URL     string `yaml:"url"`
 		Model   string `yaml:"model"`
 	} `yaml:"ollama"`
+	Variables map[string]string `yaml:"variables"`
+	Profile   string            `yaml:"profile"`
+	Versions  []Version         `yaml:"versions"`
+	Sources   []Source          `yaml:"sources"`
+}
+
+// Theme holds the template overrides and branding options
+type Theme struct {
+	Directory   string      `yaml:"directory"`    // Holds templates/ and static/ files overriding the built-in ones
+	Templates   string      `yaml:"templates"`    // Directory overriding templates/
+	Static      string      `yaml:"static"`       // Directory overriding static/
+	Logo        string      `yaml:"logo"`         // Image file or URL shown above the navigation
+	Colors      ThemeColors `yaml:"colors"`       // CSS colors replacing the defaults of style.css
+	FooterText  string      `yaml:"footer_text"`  // e.g. "© 2025 Example Corp"
+	FooterLinks []Link      `yaml:"footer_links"` // Links shown in the page footer
+}
+
+// ThemeColors overrides the color variables of style.css
+type ThemeColors struct {
+	Primary       string `yaml:"primary"`
+	PrimaryHover  string `yaml:"primary_hover"`
+	Background    string `yaml:"background"`
+	Surface       string `yaml:"surface"`
+	Border        string `yaml:"border"`
+	Text          string `yaml:"text"`
+	TextSecondary string `yaml:"text_secondary"`
+}
+
+// Link is a labelled URL
+type Link struct {
+	Text string `yaml:"text"`
+	URL  string `yaml:"url"`
+}
+
+// Locale describes one language tree of the Markdown sources
+type Locale struct {
+	Code      string `yaml:"code"`      // Language code and URL segment, e.g. "de"
+	Name      string `yaml:"name"`      // Language name, e.g. "Deutsch"
+	Directory string `yaml:"directory"` // Markdown source directory for this language
+	Default   bool   `yaml:"default"`   // Canonical language other locales link back to
+}
+
+// Version describes one documentation release built in multi-version mode
+type Version struct {
+	Name      string `yaml:"name"`      // Directory name and URL segment, e.g. "2.0"
+	Label     string `yaml:"label"`     // Name shown in the version switcher
+	Directory string `yaml:"directory"` // Markdown source directory
+	GitRef    string `yaml:"git_ref"`   // Build Directory as it is at this git ref
+	Default   bool   `yaml:"default"`   // Version served at "/"
+}
+
+// Source describes one input of a mixed-source build
+type Source struct {
+	Type     string `yaml:"type"`     // Input type, e.g. "markdown" or "pdf"
+	Path     string `yaml:"path"`     // File or directory to read
+	Mount    string `yaml:"mount"`    // Navigation heading to nest the sections under, e.g. "Vendor/Manuals"
+	Selector string `yaml:"selector"` // Content selector for "html" sources
 }
 
 // Load reads and parses the configuration file
@@ -50,6 +135,9 @@ func Load(configPath string) (*Config, error) {
 	if config.Output.Directory == "" {
 		config.Output.Directory = "docs"
 	}
+	if config.Output.BaseURL == "" {
+		config.Output.BaseURL = "/docs"
+	}
 	if config.Server.Port == "" {
 		config.Server.Port = "8080"
 	}
@@ -0,0 +1,59 @@
+package document
+
+import "regexp"
+
+// AdmonitionMarker matches the ":::type[title] body :::" markers that stand
+// for admonitions in Section.Content; the groups are the type, the optional
+// title and the body
+var AdmonitionMarker = regexp.MustCompile(`:::(\w+)(?:\[([^\]]*)\])?\s+(.*?)\s*:::`)
+
+// Section represents a documentation section with heading, content, and images
+type Section struct {
+	ID      string  // Unique identifier for the section
+	Level   int     // Heading level (1-6)
+	Heading string  // Section heading text
+	Content string  // Section text content
+	Images  []Image // Images referenced by the section
+
+	Admonitions []Admonition // Note/warning callouts in the section
+	CodeBlocks  []CodeBlock  // Fenced code blocks with their language
+	Markdown    string       // Markdown source of the section body, if any
+	HTML        string       // Pre-rendered HTML of the section body, if any
+	Source      string       // File the section was parsed from
+	CanonicalID string       // ID of the matching section in the canonical locale
+}
+
+// CodeBlock represents a fenced code block
+type CodeBlock struct {
+	Language string // Language from the fence info string (e.g. "go")
+	Code     string // Code without the fences
+}
+
+// Admonition represents a typed callout block such as a note or warning
+type Admonition struct {
+	Type    string // note, tip, important, warning, caution, ...
+	Title   string // Optional custom title
+	Content string // Callout text
+}
+
+// Image represents an image referenced from a section
+type Image struct {
+	Src      string // File name in the images directory
+	Alt      string // Alternative text
+	Title    string // Optional title, shown as caption
+	Position int    // Byte offset of the image reference in Content, -1 if not inline
+}
+
+// ImageAsset describes an image stored in the output images directory
+type ImageAsset struct {
+	Name      string   // Content-addressed file name in the images directory
+	Originals []string // Source paths that resolved to this file
+}
+
+// Document represents a parsed document
+type Document struct {
+	Title    string
+	Language string // Language code of the content, e.g. "en"
+	Sections []Section
+	Images   []ImageAsset
+}
@@ -0,0 +1,65 @@
+package document
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ImageStore writes images to the output images directory under
+// content-hash names, so identical images are stored only once and
+// different images never collide
+type ImageStore struct {
+	dir    string
+	assets map[string]*ImageAsset
+	order  []string
+}
+
+// NewImageStore creates a store writing to dir
+func NewImageStore(dir string) *ImageStore {
+	return &ImageStore{
+		dir:    dir,
+		assets: make(map[string]*ImageAsset),
+	}
+}
+
+// Save stores image data with the given extension (e.g. ".png") and returns
+// its file name. original records where the image came from.
+func (s *ImageStore) Save(data []byte, ext, original string) (string, error) {
+	// Name the file after its content
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:8]) + strings.ToLower(ext)
+
+	asset, exists := s.assets[name]
+	if !exists {
+		if err := os.MkdirAll(s.dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create image directory: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(s.dir, name), data, 0644); err != nil {
+			return "", err
+		}
+		asset = &ImageAsset{Name: name}
+		s.assets[name] = asset
+		s.order = append(s.order, name)
+	}
+
+	// Keep the original location as metadata
+	if original != "" && !slices.Contains(asset.Originals, original) {
+		asset.Originals = append(asset.Originals, original)
+	}
+
+	return name, nil
+}
+
+// Assets returns the stored images in the order they were first saved
+func (s *ImageStore) Assets() []ImageAsset {
+	assets := make([]ImageAsset, 0, len(s.order))
+	for _, name := range s.order {
+		assets = append(assets, *s.assets[name])
+	}
+	return assets
+}
@@ -0,0 +1,739 @@
+package docx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/md"
+)
+
+// headingStyleRegex matches the built-in heading style names ("heading 1")
+// and IDs ("Heading1")
+var headingStyleRegex = regexp.MustCompile(`(?i)^heading\s*([1-6])$`)
+
+// node is a generic XML element; Word documents are walked as a tree so
+// paragraphs, tables and runs keep their order
+type node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []node     `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+// attr returns the value of the attribute with the given local name
+func (n *node) attr(local string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// child returns the first child element with the given local name
+func (n *node) child(local string) *node {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == local {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// relationship is an entry of word/_rels/document.xml.rels
+type relationship struct {
+	Target   string
+	External bool
+}
+
+// Parser handles DOCX (Office Open XML) parsing
+type Parser struct {
+	outputDir string
+	images    *document.ImageStore
+	sectionID int
+}
+
+// NewParser creates a new DOCX parser
+func NewParser(outputDir string) *Parser {
+	return &Parser{
+		outputDir: outputDir,
+		images:    document.NewImageStore(filepath.Join(outputDir, "images")),
+		sectionID: 0,
+	}
+}
+
+// ParseFiles parses several DOCX files into one document
+func (p *Parser) ParseFiles(files []string) (*document.Document, error) {
+	doc := &document.Document{
+		Title:    "Documentation",
+		Sections: make([]document.Section, 0),
+	}
+
+	for i, file := range files {
+		title, sections, err := p.parseFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		if i == 0 && title != "" {
+			doc.Title = title
+		}
+		doc.Sections = append(doc.Sections, sections...)
+	}
+
+	doc.Images = p.images.Assets()
+	return doc, nil
+}
+
+// ParseDirectory parses all .docx files in a directory
+func (p *Parser) ParseDirectory(dir string) (*document.Document, error) {
+	var files []string
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// Word keeps lock files named "~$name.docx" next to open documents
+		if !info.IsDir() && strings.HasSuffix(strings.ToLower(info.Name()), ".docx") && !strings.HasPrefix(info.Name(), "~$") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no docx files found in %s", dir)
+	}
+
+	fmt.Printf("Found %d docx files\n", len(files))
+	return p.ParseFiles(files)
+}
+
+// fileParser holds the state for parsing one DOCX file
+type fileParser struct {
+	*Parser
+	path      string
+	archive   *zip.Reader
+	styles    map[string]int               // style ID -> heading level
+	titleIDs  map[string]bool              // style IDs of the "Title" style
+	rels      map[string]relationship      // relationship ID -> target
+	numbering map[string]map[string]string // numId -> level -> number format
+}
+
+// parseFile parses one DOCX file and returns its title and sections
+func (p *Parser) parseFile(filePath string) (string, []document.Section, error) {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open docx: %w", err)
+	}
+	defer r.Close()
+
+	f := &fileParser{
+		Parser:    p,
+		path:      filePath,
+		archive:   &r.Reader,
+		styles:    make(map[string]int),
+		titleIDs:  make(map[string]bool),
+		rels:      make(map[string]relationship),
+		numbering: make(map[string]map[string]string),
+	}
+
+	// Styles, relationships and numbering are optional parts
+	if err := f.loadStyles(); err != nil {
+		return "", nil, err
+	}
+	if err := f.loadRelationships(); err != nil {
+		return "", nil, err
+	}
+	if err := f.loadNumbering(); err != nil {
+		return "", nil, err
+	}
+
+	var root node
+	if err := f.readXML("word/document.xml", &root); err != nil {
+		return "", nil, err
+	}
+	body := root.child("body")
+	if body == nil {
+		return "", nil, fmt.Errorf("word/document.xml has no body")
+	}
+
+	title := f.coreTitle()
+	sections := f.parseBody(body, &title)
+	return title, sections, nil
+}
+
+// readXML decodes a part of the archive. A missing part leaves v unchanged
+// and is reported as os.ErrNotExist.
+func (f *fileParser) readXML(name string, v interface{}) error {
+	part, err := f.archive.Open(name)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+
+	if err := xml.NewDecoder(part).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// readOptionalXML decodes a part that may be missing from the archive
+func (f *fileParser) readOptionalXML(name string) (*node, error) {
+	var root node
+	if err := f.readXML(name, &root); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &root, nil
+}
+
+// loadStyles maps the IDs of heading styles to their level. Custom styles
+// based on a heading or the Title style, directly or through other styles,
+// count as that style.
+func (f *fileParser) loadStyles() error {
+	root, err := f.readOptionalXML("word/styles.xml")
+	if err != nil || root == nil {
+		return err
+	}
+
+	basedOn := make(map[string]string) // style ID -> parent style ID
+	var ids []string
+	for _, style := range root.Nodes {
+		if style.XMLName.Local != "style" {
+			continue
+		}
+		id := style.attr("styleId")
+		name := ""
+		if n := style.child("name"); n != nil {
+			name = n.attr("val")
+		}
+		if n := style.child("basedOn"); n != nil {
+			basedOn[id] = n.attr("val")
+		}
+		ids = append(ids, id)
+
+		for _, candidate := range []string{name, id} {
+			if m := headingStyleRegex.FindStringSubmatch(candidate); m != nil {
+				f.styles[id], _ = strconv.Atoi(m[1])
+				break
+			}
+		}
+		if strings.EqualFold(name, "title") || strings.EqualFold(id, "title") {
+			f.titleIDs[id] = true
+		}
+	}
+
+	// Follow basedOn chains to the nearest heading or Title style
+	for _, id := range ids {
+		seen := map[string]bool{id: true}
+		for parent := basedOn[id]; parent != "" && !seen[parent]; parent = basedOn[parent] {
+			if _, ok := f.styles[id]; ok || f.titleIDs[id] {
+				break
+			}
+			if level, ok := f.styles[parent]; ok {
+				f.styles[id] = level
+			}
+			if f.titleIDs[parent] {
+				f.titleIDs[id] = true
+			}
+			seen[parent] = true
+		}
+	}
+	return nil
+}
+
+// loadRelationships reads the targets of images and hyperlinks
+func (f *fileParser) loadRelationships() error {
+	root, err := f.readOptionalXML("word/_rels/document.xml.rels")
+	if err != nil || root == nil {
+		return err
+	}
+
+	for _, rel := range root.Nodes {
+		f.rels[rel.attr("Id")] = relationship{
+			Target:   rel.attr("Target"),
+			External: rel.attr("TargetMode") == "External",
+		}
+	}
+	return nil
+}
+
+// loadNumbering maps list numbering IDs to the number format of each level
+func (f *fileParser) loadNumbering() error {
+	root, err := f.readOptionalXML("word/numbering.xml")
+	if err != nil || root == nil {
+		return err
+	}
+
+	abstract := make(map[string]map[string]string)
+	for _, n := range root.Nodes {
+		if n.XMLName.Local != "abstractNum" {
+			continue
+		}
+		levels := make(map[string]string)
+		for _, lvl := range n.Nodes {
+			if lvl.XMLName.Local != "lvl" {
+				continue
+			}
+			if fmtNode := lvl.child("numFmt"); fmtNode != nil {
+				levels[lvl.attr("ilvl")] = fmtNode.attr("val")
+			}
+		}
+		abstract[n.attr("abstractNumId")] = levels
+	}
+
+	for _, n := range root.Nodes {
+		if n.XMLName.Local != "num" {
+			continue
+		}
+		if ref := n.child("abstractNumId"); ref != nil {
+			f.numbering[n.attr("numId")] = abstract[ref.attr("val")]
+		}
+	}
+	return nil
+}
+
+// coreTitle returns the title from the document properties, if set
+func (f *fileParser) coreTitle() string {
+	root, err := f.readOptionalXML("docProps/core.xml")
+	if err != nil || root == nil {
+		return ""
+	}
+	if title := root.child("title"); title != nil {
+		return strings.TrimSpace(title.Text)
+	}
+	return ""
+}
+
+// parseBody splits the body into sections at heading paragraphs. Content
+// before the first heading goes into a section named after the document.
+func (f *fileParser) parseBody(body *node, title *string) []document.Section {
+	sections := make([]document.Section, 0)
+
+	var current *document.Section
+	var lines []string
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Markdown = strings.TrimSpace(strings.Join(lines, "\n"))
+		md.FinishSection(current)
+		sections = append(sections, *current)
+		lines = nil
+	}
+
+	newSection := func(level int, heading string) {
+		flush()
+		f.sectionID++
+		current = &document.Section{
+			ID:      fmt.Sprintf("section-%d", f.sectionID),
+			Level:   level,
+			Heading: heading,
+			Source:  f.path,
+		}
+	}
+
+	for i := range body.Nodes {
+		n := &body.Nodes[i]
+		switch n.XMLName.Local {
+		case "p":
+			style := paragraphStyle(n)
+
+			// The Title style names the document
+			if f.titleIDs[style] {
+				if text := strings.TrimSpace(plainText(n)); text != "" && *title == "" {
+					*title = text
+				}
+				continue
+			}
+
+			if level, ok := f.styles[style]; ok {
+				if heading := strings.TrimSpace(plainText(n)); heading != "" {
+					newSection(level, heading)
+					continue
+				}
+			}
+
+			line := f.paragraph(n)
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if current == nil {
+				newSection(1, introductionHeading(*title, f.path))
+			}
+
+			// Keep consecutive list items together, separate everything else
+			if !isListLine(line) || len(lines) == 0 || !isListLine(lines[len(lines)-1]) {
+				lines = append(lines, "")
+			}
+			lines = append(lines, line)
+
+		case "tbl":
+			table := f.table(n)
+			if table == "" {
+				continue
+			}
+			if current == nil {
+				newSection(1, introductionHeading(*title, f.path))
+			}
+			lines = append(lines, "", table)
+		}
+	}
+	flush()
+
+	return sections
+}
+
+// introductionHeading names the section holding content before the first heading
+func introductionHeading(title, filePath string) string {
+	if title != "" {
+		return title
+	}
+	base := filepath.Base(filePath)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// paragraphStyle returns the style ID of a paragraph
+func paragraphStyle(p *node) string {
+	if pPr := p.child("pPr"); pPr != nil {
+		if style := pPr.child("pStyle"); style != nil {
+			return style.attr("val")
+		}
+	}
+	return ""
+}
+
+// paragraph converts a paragraph to a line of Markdown; list paragraphs
+// become list items
+func (f *fileParser) paragraph(p *node) string {
+	text := strings.TrimSpace(f.inline(p))
+	if text == "" {
+		return ""
+	}
+
+	pPr := p.child("pPr")
+	if pPr == nil {
+		return text
+	}
+	numPr := pPr.child("numPr")
+	if numPr == nil {
+		return text
+	}
+
+	numID, level := "", "0"
+	if n := numPr.child("numId"); n != nil {
+		numID = n.attr("val")
+	}
+	if n := numPr.child("ilvl"); n != nil {
+		level = n.attr("val")
+	}
+	// numId 0 removes numbering from a paragraph
+	if numID == "" || numID == "0" {
+		return text
+	}
+
+	depth, _ := strconv.Atoi(level)
+	marker := "-"
+	if format := f.numbering[numID][level]; format != "" && format != "bullet" && format != "none" {
+		marker = "1."
+	}
+	return strings.Repeat("  ", depth) + marker + " " + text
+}
+
+// isListLine reports whether a Markdown line is a list item
+func isListLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "1. ")
+}
+
+// span is a piece of run text with its formatting
+type span struct {
+	text   string
+	bold   bool
+	italic bool
+	raw    bool // already Markdown (images, links), never wrapped
+}
+
+// inline converts the runs of a paragraph or hyperlink to Markdown
+func (f *fileParser) inline(p *node) string {
+	var spans []span
+	f.collectSpans(p, &spans)
+
+	// Merge neighbouring runs with the same formatting so "**a****b**"
+	// becomes "**ab**"
+	merged := make([]span, 0, len(spans))
+	for _, s := range spans {
+		if n := len(merged); n > 0 && !s.raw && !merged[n-1].raw &&
+			merged[n-1].bold == s.bold && merged[n-1].italic == s.italic {
+			merged[n-1].text += s.text
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	var b strings.Builder
+	for _, s := range merged {
+		if s.raw {
+			b.WriteString(s.text)
+			continue
+		}
+		b.WriteString(emphasize(s))
+	}
+	return b.String()
+}
+
+// collectSpans walks runs, hyperlinks and drawings in document order
+func (f *fileParser) collectSpans(n *node, spans *[]span) {
+	for i := range n.Nodes {
+		child := &n.Nodes[i]
+		switch child.XMLName.Local {
+		case "r":
+			f.runSpans(child, spans)
+		case "hyperlink":
+			text := strings.TrimSpace(plainText(child))
+			rel, ok := f.rels[child.attr("id")]
+			if text == "" || !ok || !rel.External {
+				// Internal bookmarks have no target in the generated site
+				f.collectSpans(child, spans)
+				continue
+			}
+			*spans = append(*spans, span{text: fmt.Sprintf("[%s](%s)", md.EscapeText(stripBrackets(text)), rel.Target), raw: true})
+		case "pPr":
+			// Paragraph properties hold no text
+		default:
+			// Smart tags, content controls and field results wrap runs
+			f.collectSpans(child, spans)
+		}
+	}
+}
+
+// runSpans converts one run to spans
+func (f *fileParser) runSpans(r *node, spans *[]span) {
+	bold, italic := false, false
+	if rPr := r.child("rPr"); rPr != nil {
+		bold = toggleOn(rPr.child("b"))
+		italic = toggleOn(rPr.child("i"))
+	}
+
+	for i := range r.Nodes {
+		child := &r.Nodes[i]
+		switch child.XMLName.Local {
+		case "t":
+			*spans = append(*spans, span{text: md.EscapeText(child.Text), bold: bold, italic: italic})
+		case "tab":
+			*spans = append(*spans, span{text: " ", bold: bold, italic: italic})
+		case "br", "cr":
+			*spans = append(*spans, span{text: " ", bold: bold, italic: italic})
+		case "drawing", "pict", "object":
+			for _, image := range f.drawingImages(child) {
+				*spans = append(*spans, span{text: image, raw: true})
+			}
+		}
+	}
+}
+
+// toggleOn reports whether a run property such as <w:b/> is switched on
+func toggleOn(n *node) bool {
+	if n == nil {
+		return false
+	}
+	switch n.attr("val") {
+	case "0", "false", "off":
+		return false
+	}
+	return true
+}
+
+// emphasize wraps span text in bold/italic markers, keeping surrounding
+// whitespace outside the markers
+func emphasize(s span) string {
+	text := strings.TrimSpace(s.text)
+	if text == "" || (!s.bold && !s.italic) {
+		return s.text
+	}
+	marker := ""
+	if s.bold {
+		marker += "**"
+	}
+	if s.italic {
+		marker += "*"
+	}
+	lead := s.text[:len(s.text)-len(strings.TrimLeft(s.text, " "))]
+	trail := s.text[len(strings.TrimRight(s.text, " ")):]
+	return lead + marker + text + reverse(marker) + trail
+}
+
+// reverse reverses a marker such as "***"
+func reverse(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+// drawingImages stores the images embedded in a drawing and returns
+// Markdown references to them
+func (f *fileParser) drawingImages(drawing *node) []string {
+	refs := make([]string, 0)
+	alt := ""
+
+	var walk func(n *node)
+	walk = func(n *node) {
+		switch n.XMLName.Local {
+		case "docPr":
+			// Prefer the description, fall back to the object name
+			alt = n.attr("descr")
+			if alt == "" {
+				alt = n.attr("title")
+			}
+		case "blip", "imagedata":
+			id := n.attr("embed")
+			if id == "" {
+				id = n.attr("id")
+			}
+			if name, err := f.saveImage(id); err == nil {
+				refs = append(refs, fmt.Sprintf("![%s](images/%s)", stripBrackets(alt), name))
+			} else {
+				fmt.Printf("  Warning: %s: %v\n", f.path, err)
+			}
+		}
+		for i := range n.Nodes {
+			walk(&n.Nodes[i])
+		}
+	}
+	walk(drawing)
+
+	return refs
+}
+
+// saveImage copies the image behind a relationship ID to the images directory
+func (f *fileParser) saveImage(id string) (string, error) {
+	rel, ok := f.rels[id]
+	if !ok || rel.External {
+		return "", fmt.Errorf("image %q is not embedded", id)
+	}
+
+	// Targets are relative to the word/ directory
+	name := path.Clean(path.Join("word", rel.Target))
+	if strings.HasPrefix(rel.Target, "/") {
+		name = strings.TrimPrefix(rel.Target, "/")
+	}
+
+	part, err := f.archive.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to open image %s: %w", name, err)
+	}
+	defer part.Close()
+
+	data, err := io.ReadAll(part)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image %s: %w", name, err)
+	}
+
+	return f.images.Save(data, path.Ext(name), f.path+"#"+name)
+}
+
+// table converts a table to a Markdown pipe table; the first row is the header
+func (f *fileParser) table(tbl *node) string {
+	var rows [][]string
+	width := 0
+
+	for i := range tbl.Nodes {
+		tr := &tbl.Nodes[i]
+		if tr.XMLName.Local != "tr" {
+			continue
+		}
+		var cells []string
+		for j := range tr.Nodes {
+			tc := &tr.Nodes[j]
+			if tc.XMLName.Local != "tc" {
+				continue
+			}
+			cells = append(cells, f.cell(tc))
+		}
+		if len(cells) > width {
+			width = len(cells)
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 || width == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, cells := range rows {
+		for len(cells) < width {
+			cells = append(cells, "")
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// cell converts the paragraphs of a table cell to a single line
+func (f *fileParser) cell(tc *node) string {
+	parts := make([]string, 0)
+	for i := range tc.Nodes {
+		n := &tc.Nodes[i]
+		switch n.XMLName.Local {
+		case "p":
+			if text := strings.TrimSpace(f.inline(n)); text != "" {
+				parts = append(parts, text)
+			}
+		case "tbl":
+			// Nested tables cannot be expressed in a pipe table cell
+			if text := strings.TrimSpace(plainText(n)); text != "" {
+				parts = append(parts, md.EscapeText(text))
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// plainText returns the text of all runs below n
+func plainText(n *node) string {
+	var b strings.Builder
+	var walk func(n *node)
+	walk = func(n *node) {
+		switch n.XMLName.Local {
+		case "t":
+			b.WriteString(n.Text)
+			return
+		case "tab", "br", "cr":
+			b.WriteString(" ")
+			return
+		case "p":
+			if b.Len() > 0 {
+				b.WriteString(" ")
+			}
+		}
+		for i := range n.Nodes {
+			walk(&n.Nodes[i])
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// stripBrackets removes square brackets, which would end link and image text early
+func stripBrackets(text string) string {
+	return strings.NewReplacer("[", "", "]", "").Replace(text)
+}
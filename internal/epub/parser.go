@@ -0,0 +1,299 @@
+package epub
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/htmldoc"
+)
+
+// container is META-INF/container.xml, which points at the package document
+type container struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// packageDocument is the OPF file with the book metadata, its files and
+// their reading order
+type packageDocument struct {
+	Metadata struct {
+		Titles    []string `xml:"title"`
+		Languages []string `xml:"language"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef  string `xml:"idref,attr"`
+		Linear string `xml:"linear,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// Parser reads EPUB books. Chapters are XHTML, so they are converted with
+// the HTML source after the book has been unpacked.
+type Parser struct {
+	outputDir string
+}
+
+// NewParser creates a new EPUB parser
+func NewParser(outputDir string) *Parser {
+	return &Parser{outputDir: outputDir}
+}
+
+// Parse reads an EPUB file. The chapters are read in spine order and split
+// into sections at their headings.
+func (p *Parser) Parse(epubPath string) (*document.Document, error) {
+	r, err := zip.OpenReader(epubPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open epub: %w", err)
+	}
+	defer r.Close()
+
+	// Locate the package document
+	var c container
+	if err := readXML(&r.Reader, "META-INF/container.xml", &c); err != nil {
+		return nil, err
+	}
+	opfPath := ""
+	for _, rootfile := range c.Rootfiles {
+		if rootfile.MediaType == "" || rootfile.MediaType == "application/oebps-package+xml" {
+			opfPath = rootfile.FullPath
+			break
+		}
+	}
+	if opfPath == "" {
+		return nil, fmt.Errorf("no package document in META-INF/container.xml")
+	}
+
+	var pkg packageDocument
+	if err := readXML(&r.Reader, opfPath, &pkg); err != nil {
+		return nil, err
+	}
+
+	// Chapters in reading order; the navigation document is skipped
+	// because the site builds its own navigation. Non-linear chapters such
+	// as notes are auxiliary, so they follow the linear ones.
+	items := make(map[string]int)
+	for i, item := range pkg.Manifest {
+		items[item.ID] = i
+	}
+	opfDir := path.Dir(opfPath)
+	var linear, auxiliary []string
+	for _, ref := range pkg.Spine {
+		idx, ok := items[ref.IDRef]
+		if !ok {
+			continue
+		}
+		item := pkg.Manifest[idx]
+		if containsWord(item.Properties, "nav") || !isXHTML(item.MediaType) {
+			continue
+		}
+		name, ok := manifestPath(opfDir, item.Href)
+		if !ok {
+			fmt.Printf("  Warning: skipping chapter with invalid href: %s\n", item.Href)
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(ref.Linear), "no") {
+			auxiliary = append(auxiliary, name)
+		} else {
+			linear = append(linear, name)
+		}
+	}
+	names := append(linear, auxiliary...)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no chapters in the spine of %s", opfPath)
+	}
+	fmt.Printf("Found %d chapters\n", len(names))
+
+	// The images of the manifest are unpacked with the chapters so that
+	// the chapters can reference them
+	files := append([]string(nil), names...)
+	for _, item := range pkg.Manifest {
+		if !strings.HasPrefix(item.MediaType, "image/") {
+			continue
+		}
+		if name, ok := manifestPath(opfDir, item.Href); ok {
+			files = append(files, name)
+		}
+	}
+
+	tempDir, err := os.MkdirTemp("", "doctrainer-epub-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := unpack(&r.Reader, files, tempDir); err != nil {
+		return nil, err
+	}
+
+	var chapters []string
+	for _, name := range names {
+		chapters = append(chapters, filepath.Join(tempDir, filepath.FromSlash(name)))
+	}
+
+	parser, err := htmldoc.NewParser(p.outputDir, "body")
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parser.ParseFiles(chapters)
+	if err != nil {
+		return nil, err
+	}
+
+	// Book metadata takes precedence over chapter titles
+	if len(pkg.Metadata.Titles) > 0 && strings.TrimSpace(pkg.Metadata.Titles[0]) != "" {
+		doc.Title = strings.TrimSpace(pkg.Metadata.Titles[0])
+	}
+	if len(pkg.Metadata.Languages) > 0 {
+		doc.Language = strings.TrimSpace(pkg.Metadata.Languages[0])
+	}
+
+	// Report locations inside the book instead of the temp directory
+	inBook := func(p string) string {
+		rel, err := filepath.Rel(tempDir, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return p
+		}
+		return epubPath + "#" + filepath.ToSlash(rel)
+	}
+	for i := range doc.Sections {
+		doc.Sections[i].Source = inBook(doc.Sections[i].Source)
+	}
+	for i := range doc.Images {
+		for j, original := range doc.Images[i].Originals {
+			doc.Images[i].Originals[j] = inBook(original)
+		}
+	}
+
+	return doc, nil
+}
+
+// readXML decodes an XML file of the archive
+func readXML(r *zip.Reader, name string, v interface{}) error {
+	f, err := r.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer f.Close()
+
+	if err := xml.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// maxEntrySize and maxUnpackedSize limit how much of a book is extracted,
+// so a crafted archive cannot fill the disk
+const (
+	maxEntrySize    = 64 << 20
+	maxUnpackedSize = 512 << 20
+)
+
+// manifestPath resolves a URL-encoded manifest href against the directory
+// of the package document. It reports false for hrefs outside the book.
+func manifestPath(opfDir, href string) (string, bool) {
+	decoded, err := url.PathUnescape(href)
+	if err != nil {
+		return "", false
+	}
+	if i := strings.IndexByte(decoded, '#'); i >= 0 {
+		decoded = decoded[:i]
+	}
+	name := path.Join(opfDir, decoded)
+	if decoded == "" || path.IsAbs(decoded) || name == ".." || strings.HasPrefix(name, "../") {
+		return "", false
+	}
+	return name, true
+}
+
+// unpack extracts the named entries of the archive into dir. Missing
+// entries are reported and skipped; entries above the size limits fail.
+func unpack(r *zip.Reader, names []string, dir string) error {
+	entries := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		entries[path.Clean(f.Name)] = f
+	}
+
+	var total int64
+	extracted := make(map[string]bool)
+	for _, name := range names {
+		if extracted[name] {
+			continue
+		}
+		extracted[name] = true
+
+		f, ok := entries[name]
+		if !ok || f.FileInfo().IsDir() {
+			fmt.Printf("  Warning: %s is listed in the manifest but missing from the epub\n", name)
+			continue
+		}
+
+		dest := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		n, err := extractFile(f, dest, min(maxEntrySize, maxUnpackedSize-total))
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", f.Name, err)
+		}
+		total += n
+	}
+	return nil
+}
+
+// extractFile writes one archive entry to dest and returns its size. The
+// declared size is not trusted: reading stops after limit bytes.
+func extractFile(f *zip.File, dest string, limit int64) (int64, error) {
+	if f.UncompressedSize64 > uint64(limit) {
+		return 0, fmt.Errorf("file is larger than %d bytes", limit)
+	}
+	src, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	n, err := io.Copy(out, io.LimitReader(src, limit+1))
+	if err != nil {
+		return n, err
+	}
+	if n > limit {
+		return n, fmt.Errorf("file is larger than %d bytes", limit)
+	}
+	return n, nil
+}
+
+// isXHTML reports whether a manifest media type is a content document
+func isXHTML(mediaType string) bool {
+	return mediaType == "application/xhtml+xml" || mediaType == "text/html"
+}
+
+// containsWord reports whether a space-separated list contains word
+func containsWord(list, word string) bool {
+	for _, w := range strings.Fields(list) {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
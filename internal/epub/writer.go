@@ -0,0 +1,565 @@
+package epub
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"docTrainerGO/internal/document"
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// containerXML points reading systems at the package document
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// voidElements are written as empty elements in XHTML
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// foreignNamespaces maps the namespaces of embedded SVG and MathML, as the
+// HTML parser names them, to their XML namespace
+var foreignNamespaces = map[string]string{
+	"svg":  "http://www.w3.org/2000/svg",
+	"math": "http://www.w3.org/1998/Math/MathML",
+}
+
+// xmlNameRegex matches attribute names that are valid in XML
+var xmlNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.:-]*$`)
+
+// sectionLinkRegex matches links to sections of the document
+var sectionLinkRegex = regexp.MustCompile(`^#(section-\d+)$`)
+
+// Writer builds EPUB 3 books from documents. Section HTML is converted to
+// XHTML, with one file per chapter.
+type Writer struct {
+	imagesDir  string // images directory of the build
+	author     string
+	stylesheet []byte
+}
+
+// NewWriter creates a new EPUB writer reading images from imagesDir
+func NewWriter(imagesDir string) *Writer {
+	return &Writer{imagesDir: imagesDir}
+}
+
+// SetAuthor sets the author recorded in the book metadata
+func (w *Writer) SetAuthor(author string) {
+	w.author = author
+}
+
+// SetStylesheet sets the CSS shared by all chapters
+func (w *Writer) SetStylesheet(css []byte) {
+	w.stylesheet = css
+}
+
+// chapter is one XHTML file of the book
+type chapter struct {
+	file     string
+	sections []document.Section
+}
+
+// navNode is an entry of the navigation document
+type navNode struct {
+	section  document.Section
+	children []*navNode
+}
+
+// Write creates the EPUB file
+func (w *Writer) Write(doc *document.Document, path string) error {
+	chapters := splitChapters(doc.Sections)
+	if len(chapters) == 0 {
+		return fmt.Errorf("document has no sections")
+	}
+
+	lang := doc.Language
+	if lang == "" {
+		lang = "en"
+	}
+
+	// Section links point into the chapter holding the section
+	files := make(map[string]string, len(doc.Sections))
+	for _, ch := range chapters {
+		for _, section := range ch.sections {
+			files[section.ID] = ch.file
+		}
+	}
+
+	// Render the chapters, collecting the images they use
+	images := make(map[string]bool)
+	bodies := make([]string, len(chapters))
+	for i := range chapters {
+		body, err := w.renderChapter(&chapters[i], lang, files, images)
+		if err != nil {
+			return err
+		}
+		bodies[i] = body
+	}
+	names := make([]string, 0, len(images))
+	for name := range images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Images from the build's images directory; unreadable ones are skipped
+	imageData := make(map[string][]byte, len(names))
+	kept := names[:0]
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(w.imagesDir, filepath.FromSlash(name)))
+		if err != nil {
+			fmt.Printf("  Warning: skipping image %s: %v\n", name, err)
+			continue
+		}
+		imageData[name] = data
+		kept = append(kept, name)
+	}
+	names = kept
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create export directory: %w", err)
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create epub: %w", err)
+	}
+	defer file.Close()
+	zw := zip.NewWriter(file)
+
+	// The mimetype must come first and be stored uncompressed
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return fmt.Errorf("failed to write mimetype: %w", err)
+	}
+	if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return fmt.Errorf("failed to write mimetype: %w", err)
+	}
+
+	entries := []struct {
+		name string
+		data string
+	}{
+		{"META-INF/container.xml", containerXML},
+		{"OEBPS/style.css", string(w.stylesheet)},
+		{"OEBPS/nav.xhtml", navDocument(doc, lang, files)},
+		{"OEBPS/content.opf", w.packageDocument(doc, lang, chapters, names)},
+	}
+	for i, ch := range chapters {
+		entries = append(entries, struct {
+			name string
+			data string
+		}{"OEBPS/" + ch.file, bodies[i]})
+	}
+	for _, entry := range entries {
+		if err := writeEntry(zw, entry.name, []byte(entry.data)); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		if err := writeEntry(zw, "OEBPS/images/"+name, imageData[name]); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish epub: %w", err)
+	}
+
+	fmt.Printf("Generated: %s (%d chapters, %d images)\n", path, len(chapters), len(names))
+	return nil
+}
+
+// splitChapters groups sections into chapters, starting a new chapter at
+// every section of the highest heading level
+func splitChapters(sections []document.Section) []chapter {
+	top := 0
+	for _, section := range sections {
+		if top == 0 || section.Level < top {
+			top = section.Level
+		}
+	}
+
+	var chapters []chapter
+	for _, section := range sections {
+		if len(chapters) == 0 || section.Level <= top {
+			chapters = append(chapters, chapter{file: fmt.Sprintf("chapter-%d.xhtml", len(chapters)+1)})
+		}
+		last := &chapters[len(chapters)-1]
+		last.sections = append(last.sections, section)
+	}
+	return chapters
+}
+
+// renderChapter writes the XHTML file of a chapter
+func (w *Writer) renderChapter(ch *chapter, lang string, files map[string]string, images map[string]bool) (string, error) {
+	var b strings.Builder
+	b.WriteString(xhtmlHeader(ch.sections[0].Heading, lang))
+
+	for _, section := range ch.sections {
+		level := min(max(section.Level, 1), 6)
+		fmt.Fprintf(&b, "<section id=\"%s\">\n<h%d>%s</h%d>\n", html.EscapeString(section.ID), level, html.EscapeString(section.Heading), level)
+
+		// Section body, as pre-rendered HTML or plain paragraphs
+		body := section.HTML
+		if body == "" {
+			body = paragraphs(section.Content)
+		}
+		xhtml, err := toXHTML(body, func(n *nethtml.Node) {
+			switch n.Data {
+			case "a":
+				for i, attr := range n.Attr {
+					if attr.Key != "href" {
+						continue
+					}
+					if match := sectionLinkRegex.FindStringSubmatch(attr.Val); match != nil && files[match[1]] != ch.file && files[match[1]] != "" {
+						n.Attr[i].Val = files[match[1]] + attr.Val
+					}
+				}
+			case "img":
+				w.rewriteImage(n, images)
+			}
+		})
+		if err != nil {
+			// One broken section should not cost the whole book
+			fmt.Printf("  Warning: failed to convert %s to XHTML, writing it as plain text: %v\n", section.ID, err)
+			xhtml = paragraphs(section.Content)
+		}
+		b.WriteString(xhtml)
+
+		// Images without an inline position (e.g. from PDFs) follow the section
+		for _, image := range section.Images {
+			if image.Position >= 0 {
+				continue
+			}
+			name, err := w.checkImage(image.Src)
+			if err != nil {
+				fmt.Printf("  Warning: skipping image %s in %s: %v\n", image.Src, section.ID, err)
+				continue
+			}
+			images[name] = true
+			fmt.Fprintf(&b, "<figure><img src=\"%s\" alt=\"%s\"/>", html.EscapeString(imageHref(name)), html.EscapeString(image.Alt))
+			if image.Title != "" {
+				fmt.Fprintf(&b, "<figcaption>%s</figcaption>", html.EscapeString(image.Title))
+			}
+			b.WriteString("</figure>\n")
+		}
+
+		b.WriteString("</section>\n")
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	return b.String(), nil
+}
+
+// rewriteImage points an <img> at its file in the book. EPUB 3 does not
+// allow remote images, so those become a link labelled with the alt text;
+// images that are missing or outside the images directory become their alt
+// text.
+func (w *Writer) rewriteImage(n *nethtml.Node, images map[string]bool) {
+	src, alt := "", ""
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "src":
+			src = attr.Val
+		case "alt":
+			alt = attr.Val
+		}
+	}
+
+	u, err := url.Parse(src)
+	switch {
+	case err == nil && u.Scheme == "data":
+		// Inline images are part of the chapter
+		return
+	case err == nil && (u.Scheme != "" || u.Host != ""):
+		if alt == "" {
+			alt = src
+		}
+		if insideLink(n) {
+			// Links cannot be nested, so only the alt text is kept
+			replaceWithText(n, alt)
+			return
+		}
+		n.Data, n.DataAtom = "a", atom.A
+		n.Attr = []nethtml.Attribute{{Key: "href", Val: src}}
+		n.AppendChild(&nethtml.Node{Type: nethtml.TextNode, Data: alt})
+		return
+	}
+
+	name := ""
+	if err == nil {
+		var ok bool
+		if name, ok = strings.CutPrefix(u.Path, "images/"); !ok {
+			err = fmt.Errorf("not in the images directory")
+		}
+	}
+	if err == nil {
+		name, err = w.checkImage(name)
+	}
+	if err != nil {
+		fmt.Printf("  Warning: skipping image %s: %v\n", src, err)
+		replaceWithText(n, alt)
+		return
+	}
+
+	images[name] = true
+	for i, attr := range n.Attr {
+		if attr.Key == "src" {
+			n.Attr[i].Val = imageHref(name)
+		}
+	}
+}
+
+// checkImage cleans the name of an image in the images directory and
+// checks that the file exists there
+func (w *Writer) checkImage(name string) (string, error) {
+	name = path.Clean(name)
+	if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return "", fmt.Errorf("path leaves the images directory")
+	}
+	info, err := os.Stat(filepath.Join(w.imagesDir, filepath.FromSlash(name)))
+	if err != nil || info.IsDir() {
+		return "", fmt.Errorf("image file not found")
+	}
+	return name, nil
+}
+
+// imageHref returns the URL of an image file from the chapters and the
+// package document
+func imageHref(name string) string {
+	return (&url.URL{Path: "images/" + name}).EscapedPath()
+}
+
+// insideLink reports whether n is inside an <a> element
+func insideLink(n *nethtml.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == nethtml.ElementNode && p.Data == "a" {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceWithText turns n into a text node
+func replaceWithText(n *nethtml.Node, text string) {
+	n.Type, n.Data, n.DataAtom, n.Attr = nethtml.TextNode, text, 0, nil
+}
+
+// toXHTML converts an HTML fragment to well-formed XHTML. The fragment is
+// parsed like a browser would, so unclosed and mis-nested tags are repaired,
+// then written back with void elements closed, comments dropped and text
+// escaped. rewrite is called with every element before it is written and
+// may change it.
+func toXHTML(fragment string, rewrite func(n *nethtml.Node)) (string, error) {
+	context := &nethtml.Node{Type: nethtml.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := nethtml.ParseFragment(strings.NewReader(fragment), context)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, n := range nodes {
+		writeXHTML(&b, n, rewrite)
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+// writeXHTML writes a parsed node and its children as XHTML
+func writeXHTML(b *strings.Builder, n *nethtml.Node, rewrite func(n *nethtml.Node)) {
+	if n.Type == nethtml.ElementNode {
+		rewrite(n)
+	}
+	switch n.Type {
+	case nethtml.TextNode:
+		b.WriteString(html.EscapeString(xmlText(n.Data)))
+	case nethtml.ElementNode:
+		name := n.Data
+		b.WriteString("<" + name)
+		// Embedded SVG and MathML declare their namespace at their root
+		if ns := foreignNamespaces[n.Namespace]; ns != "" && (n.Parent == nil || n.Parent.Namespace != n.Namespace) {
+			fmt.Fprintf(b, " xmlns=\"%s\"", ns)
+		}
+		for _, attr := range n.Attr {
+			// Namespaced attributes and names XML does not allow are dropped
+			if attr.Namespace != "" || strings.HasPrefix(attr.Key, "xmlns") || !xmlNameRegex.MatchString(attr.Key) {
+				continue
+			}
+			fmt.Fprintf(b, " %s=\"%s\"", attr.Key, html.EscapeString(xmlText(attr.Val)))
+		}
+		if voidElements[name] {
+			b.WriteString("/>")
+			return
+		}
+		b.WriteString(">")
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			writeXHTML(b, c, rewrite)
+		}
+		b.WriteString("</" + name + ">")
+	}
+}
+
+// xmlText drops the control characters XML does not allow
+func xmlText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' || r == 0xFFFE || r == 0xFFFF {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// paragraphs renders plain section text as XHTML paragraphs
+func paragraphs(content string) string {
+	var b strings.Builder
+	for _, para := range strings.Split(content, "\n\n") {
+		if para = strings.TrimSpace(para); para != "" {
+			b.WriteString("<p>" + html.EscapeString(xmlText(para)) + "</p>\n")
+		}
+	}
+	return b.String()
+}
+
+// navDocument writes the EPUB navigation document from the section tree
+func navDocument(doc *document.Document, lang string, files map[string]string) string {
+	var b strings.Builder
+	b.WriteString(xhtmlHeader(doc.Title, lang))
+	fmt.Fprintf(&b, "<nav epub:type=\"toc\" id=\"toc\">\n<h1>%s</h1>\n", html.EscapeString(doc.Title))
+	writeNavList(&b, navTree(doc.Sections), files)
+	b.WriteString("</nav>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// navTree nests sections below the preceding section of a higher level
+func navTree(sections []document.Section) []*navNode {
+	var roots []*navNode
+	var stack []*navNode
+	for _, section := range sections {
+		node := &navNode{section: section}
+		for len(stack) > 0 && stack[len(stack)-1].section.Level >= section.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+		}
+		stack = append(stack, node)
+	}
+	return roots
+}
+
+// writeNavList writes a nested ordered list of navigation links
+func writeNavList(b *strings.Builder, nodes []*navNode, files map[string]string) {
+	b.WriteString("<ol>\n")
+	for _, node := range nodes {
+		fmt.Fprintf(b, "<li><a href=\"%s#%s\">%s</a>", files[node.section.ID], html.EscapeString(node.section.ID), html.EscapeString(node.section.Heading))
+		if len(node.children) > 0 {
+			b.WriteString("\n")
+			writeNavList(b, node.children, files)
+		}
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</ol>\n")
+}
+
+// packageDocument writes the OPF file with the metadata, the manifest of
+// all files and the reading order
+func (w *Writer) packageDocument(doc *document.Document, lang string, chapters []chapter, images []string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&b, "<package xmlns=\"http://www.idpf.org/2007/opf\" version=\"3.0\" unique-identifier=\"book-id\" xml:lang=\"%s\">\n", html.EscapeString(lang))
+
+	// Metadata
+	b.WriteString("<metadata xmlns:dc=\"http://purl.org/dc/elements/1.1/\">\n")
+	fmt.Fprintf(&b, "<dc:identifier id=\"book-id\">%s</dc:identifier>\n", bookID(doc.Title, w.author))
+	fmt.Fprintf(&b, "<dc:title>%s</dc:title>\n", html.EscapeString(doc.Title))
+	fmt.Fprintf(&b, "<dc:language>%s</dc:language>\n", html.EscapeString(lang))
+	if w.author != "" {
+		fmt.Fprintf(&b, "<dc:creator>%s</dc:creator>\n", html.EscapeString(w.author))
+	}
+	fmt.Fprintf(&b, "<meta property=\"dcterms:modified\">%s</meta>\n", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	b.WriteString("</metadata>\n")
+
+	// Manifest
+	b.WriteString("<manifest>\n")
+	b.WriteString("<item id=\"nav\" href=\"nav.xhtml\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>\n")
+	b.WriteString("<item id=\"style\" href=\"style.css\" media-type=\"text/css\"/>\n")
+	for i, ch := range chapters {
+		fmt.Fprintf(&b, "<item id=\"chapter-%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", i+1, ch.file)
+	}
+	for i, name := range images {
+		mediaType := mime.TypeByExtension(filepath.Ext(name))
+		if mediaType == "" {
+			mediaType = "application/octet-stream"
+		}
+		fmt.Fprintf(&b, "<item id=\"image-%d\" href=\"%s\" media-type=\"%s\"/>\n", i+1, html.EscapeString(imageHref(name)), mediaType)
+	}
+	b.WriteString("</manifest>\n")
+
+	// Reading order
+	b.WriteString("<spine>\n")
+	for i := range chapters {
+		fmt.Fprintf(&b, "<itemref idref=\"chapter-%d\"/>\n", i+1)
+	}
+	b.WriteString("</spine>\n</package>\n")
+	return b.String()
+}
+
+// xhtmlHeader starts an XHTML content document
+func xhtmlHeader(title, lang string) string {
+	lang = html.EscapeString(lang)
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%s" lang="%s">
+<head>
+<meta charset="UTF-8"/>
+<title>%s</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+`, lang, lang, html.EscapeString(title))
+}
+
+// bookID derives a stable identifier from the title and author, so
+// re-exports replace the book on reading systems
+func bookID(title, author string) string {
+	sum := sha1.Sum([]byte(title + "\x00" + author))
+	sum[6] = sum[6]&0x0f | 0x50 // version 5
+	sum[8] = sum[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// writeEntry adds a compressed file to the book
+func writeEntry(zw *zip.Writer, name string, data []byte) error {
+	entry, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := entry.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
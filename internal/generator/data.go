@@ -6,23 +6,62 @@ import (
 	"os"
 	"path/filepath"
 
-	"docTrainerGO/internal/pdf"
+	"docTrainerGO/internal/document"
 )
 
 // ContentData represents the structured content storage
 type ContentData struct {
 	Title    string           `json:"title"`
+	Language string           `json:"language,omitempty"`
 	Sections []SectionData    `json:"sections"`
+	TOC      []*TOCNode       `json:"toc"`
+	Images   []ImageData      `json:"images"`
 	Metadata DocumentMetadata `json:"metadata"`
 }
 
+// ImageRef represents an image placed in a section
+type ImageRef struct {
+	Src      string `json:"src"`
+	Alt      string `json:"alt"`
+	Title    string `json:"title,omitempty"`
+	Position int    `json:"position"`
+}
+
+// Admonition represents a typed callout block (note, warning, ...)
+type Admonition struct {
+	Type    string `json:"type"`
+	Title   string `json:"title,omitempty"`
+	Content string `json:"content"`
+}
+
+// CodeBlock represents a fenced code block and its language
+type CodeBlock struct {
+	Language string `json:"language"`
+	Code     string `json:"code"`
+}
+
+// ImageData maps a stored image file to the source paths it came from
+type ImageData struct {
+	Name      string   `json:"name"`
+	Originals []string `json:"originals"`
+}
+
 // SectionData represents a single section with all its data
 type SectionData struct {
-	ID      string   `json:"id"`
-	Level   int      `json:"level"`
-	Heading string   `json:"heading"`
-	Content string   `json:"content"`
-	Images  []string `json:"images"`
+	ID          string       `json:"id"`
+	Level       int          `json:"level"`
+	Heading     string       `json:"heading"`
+	Content     string       `json:"content"`
+	Images      []ImageRef   `json:"images"`
+	Admonitions []Admonition `json:"admonitions"`
+	CodeBlocks  []CodeBlock  `json:"code_blocks"`
+	HTML        string       `json:"html,omitempty"`
+	CanonicalID string       `json:"canonical_id,omitempty"`
+	ParentID    string       `json:"parent_id,omitempty"`
+	ChildIDs    []string     `json:"child_ids"`
+	Breadcrumbs []Breadcrumb `json:"breadcrumbs"`
+	PrevID      string       `json:"prev_id,omitempty"`
+	NextID      string       `json:"next_id,omitempty"`
 }
 
 // DocumentMetadata contains document-level information
@@ -44,31 +83,37 @@ func NewDataGenerator(outputDir string) *DataGenerator {
 }
 
 // Generate creates structured JSON data files
-func (dg *DataGenerator) Generate(doc *pdf.Document) error {
+func (dg *DataGenerator) Generate(doc *document.Document) error {
 	// Create data directory
 	dataDir := filepath.Join(dg.outputDir, "data")
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	// Convert sections to data format
-	sections := make([]SectionData, len(doc.Sections))
+	// Convert sections to data format with the nested table of contents
+	// and navigation links
+	sections, toc := convertSections(doc.Sections)
 	totalImages := 0
-	for i, section := range doc.Sections {
-		sections[i] = SectionData{
-			ID:      section.ID,
-			Level:   section.Level,
-			Heading: section.Heading,
-			Content: section.Content,
-			Images:  section.Images,
-		}
+	for _, section := range doc.Sections {
 		totalImages += len(section.Images)
 	}
 
+	// Convert image metadata
+	images := make([]ImageData, len(doc.Images))
+	for i, image := range doc.Images {
+		images[i] = ImageData{
+			Name:      image.Name,
+			Originals: image.Originals,
+		}
+	}
+
 	// Create content data
 	contentData := ContentData{
 		Title:    doc.Title,
+		Language: doc.Language,
 		Sections: sections,
+		TOC:      toc,
+		Images:   images,
 		Metadata: DocumentMetadata{
 			TotalSections: len(sections),
 			TotalImages:   totalImages,
@@ -77,7 +122,7 @@ func (dg *DataGenerator) Generate(doc *pdf.Document) error {
 
 	// Save main content.json
 	contentPath := filepath.Join(dataDir, "content.json")
-	if err := dg.saveJSON(contentPath, contentData); err != nil {
+	if err := saveJSON(contentPath, contentData); err != nil {
 		return fmt.Errorf("failed to save content.json: %w", err)
 	}
 
@@ -91,7 +136,7 @@ func (dg *DataGenerator) Generate(doc *pdf.Document) error {
 
 	for _, section := range sections {
 		sectionPath := filepath.Join(sectionsDir, fmt.Sprintf("%s.json", section.ID))
-		if err := dg.saveJSON(sectionPath, section); err != nil {
+		if err := saveJSON(sectionPath, section); err != nil {
 			return fmt.Errorf("failed to save section %s: %w", section.ID, err)
 		}
 	}
@@ -101,8 +146,130 @@ func (dg *DataGenerator) Generate(doc *pdf.Document) error {
 	return nil
 }
 
+// convertSections converts sections to their data format and builds the
+// table of contents linking them
+func convertSections(docSections []document.Section) ([]SectionData, []*TOCNode) {
+	sections := make([]SectionData, len(docSections))
+	for i, section := range docSections {
+		sections[i] = SectionData{
+			ID:          section.ID,
+			Level:       section.Level,
+			Heading:     section.Heading,
+			Content:     section.Content,
+			Images:      convertImages(section.Images),
+			Admonitions: convertAdmonitions(section.Admonitions),
+			CodeBlocks:  convertCodeBlocks(section.CodeBlocks),
+			HTML:        section.HTML,
+			CanonicalID: section.CanonicalID,
+			ChildIDs:    make([]string, 0),
+		}
+	}
+
+	toc := buildTOC(sections)
+	return sections, toc
+}
+
+// convertImages converts section images to their data format
+func convertImages(images []document.Image) []ImageRef {
+	refs := make([]ImageRef, len(images))
+	for i, image := range images {
+		refs[i] = ImageRef{
+			Src:      image.Src,
+			Alt:      image.Alt,
+			Title:    image.Title,
+			Position: image.Position,
+		}
+	}
+	return refs
+}
+
+// convertAdmonitions converts section admonitions to their data format
+func convertAdmonitions(admonitions []document.Admonition) []Admonition {
+	blocks := make([]Admonition, len(admonitions))
+	for i, admonition := range admonitions {
+		blocks[i] = Admonition{
+			Type:    admonition.Type,
+			Title:   admonition.Title,
+			Content: admonition.Content,
+		}
+	}
+	return blocks
+}
+
+// convertCodeBlocks converts section code blocks to their data format
+func convertCodeBlocks(codeBlocks []document.CodeBlock) []CodeBlock {
+	blocks := make([]CodeBlock, len(codeBlocks))
+	for i, codeBlock := range codeBlocks {
+		blocks[i] = CodeBlock{
+			Language: codeBlock.Language,
+			Code:     codeBlock.Code,
+		}
+	}
+	return blocks
+}
+
+// LoadDocument reads the content.json of a build back into a document, for
+// exports that run after the build
+func LoadDocument(outputDir string) (*document.Document, error) {
+	file, err := os.Open(filepath.Join(outputDir, "data", "content.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open content.json: %w", err)
+	}
+	defer file.Close()
+
+	var content ContentData
+	if err := json.NewDecoder(file).Decode(&content); err != nil {
+		return nil, fmt.Errorf("failed to parse content.json: %w", err)
+	}
+
+	doc := &document.Document{
+		Title:    content.Title,
+		Language: content.Language,
+		Sections: make([]document.Section, len(content.Sections)),
+		Images:   make([]document.ImageAsset, len(content.Images)),
+	}
+	for i, section := range content.Sections {
+		doc.Sections[i] = document.Section{
+			ID:          section.ID,
+			Level:       section.Level,
+			Heading:     section.Heading,
+			Content:     section.Content,
+			HTML:        section.HTML,
+			CanonicalID: section.CanonicalID,
+		}
+		for _, image := range section.Images {
+			doc.Sections[i].Images = append(doc.Sections[i].Images, document.Image{
+				Src:      image.Src,
+				Alt:      image.Alt,
+				Title:    image.Title,
+				Position: image.Position,
+			})
+		}
+		for _, admonition := range section.Admonitions {
+			doc.Sections[i].Admonitions = append(doc.Sections[i].Admonitions, document.Admonition{
+				Type:    admonition.Type,
+				Title:   admonition.Title,
+				Content: admonition.Content,
+			})
+		}
+		for _, codeBlock := range section.CodeBlocks {
+			doc.Sections[i].CodeBlocks = append(doc.Sections[i].CodeBlocks, document.CodeBlock{
+				Language: codeBlock.Language,
+				Code:     codeBlock.Code,
+			})
+		}
+	}
+	for i, image := range content.Images {
+		doc.Images[i] = document.ImageAsset{
+			Name:      image.Name,
+			Originals: image.Originals,
+		}
+	}
+	return doc, nil
+}
+
 // saveJSON writes data to a JSON file
-func (dg *DataGenerator) saveJSON(path string, data interface{}) error {
+func saveJSON(path string, data interface{}) error {
 	file, err := os.Create(path)
 	if err != nil {
 		return err
@@ -2,59 +2,128 @@ package generator
 
 import (
 	"fmt"
-	"html/template"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
-	"docTrainerGO/internal/pdf"
+	"docTrainerGO/internal/document"
 )
 
 // Generator handles HTML page generation
 type Generator struct {
+	files        fs.FS // templates and static assets
 	templatePath string
 	outputDir    string
+	docsBase     string // URL of the output directory as seen from the page
+	staticIndex  string // URL of the static pages, linked for readers without JavaScript
+	theme        ThemeData
+	chatEnabled  bool
 }
 
 // NewGenerator creates a new HTML generator
-func NewGenerator(templatePath, outputDir string) *Generator {
+func NewGenerator(files fs.FS, templatePath, outputDir string) *Generator {
 	return &Generator{
+		files:        files,
 		templatePath: templatePath,
 		outputDir:    outputDir,
+		docsBase:     "/docs",
 	}
 }
 
-// PageData represents data passed to HTML templates (minimal - just metadata)
+// PageData represents data passed to HTML templates: the page, the
+// navigation, the theme and build metadata
 type PageData struct {
-	Title string
+	Title       string // page title; the documentation title on the start page
+	DocTitle    string
+	Lang        string
+	HomeURL     string // start page of the documentation, "" on the start page itself
+	DocsBase    string // URL of the output directory (data, images) as seen from the page
+	Static      bool   // complete page that works without JavaScript
+	StaticIndex string // static pages index, linked for readers without JavaScript
+	ChatEnabled bool
+	Nav         []*NavItem
+	Theme       ThemeData
+	Meta        PageMeta
+	Offline     *OfflineAssets // inlined assets of a single-file export, nil otherwise
+}
+
+// PageMeta describes the build a page belongs to
+type PageMeta struct {
+	Generator string
+	Generated string // build date, YYYY-MM-DD
+	Sections  int
+}
+
+// AssetURL resolves a path relative to the output directory (e.g. the logo)
+// for this page; absolute URLs are returned unchanged
+func (d PageData) AssetURL(asset string) string {
+	if isAbsoluteURL(asset) {
+		return asset
+	}
+	return strings.TrimSuffix(d.DocsBase, "/") + "/" + asset
+}
+
+// SetStaticIndex links the shell page to the static pages, for crawlers and
+// browsers without JavaScript
+func (g *Generator) SetStaticIndex(url string) {
+	g.staticIndex = url
+}
+
+// SetDocsBase sets the URL the output directory is served at
+func (g *Generator) SetDocsBase(url string) {
+	g.docsBase = strings.TrimSuffix(url, "/")
+}
+
+// SetTheme sets the branding options passed to the templates
+func (g *Generator) SetTheme(theme ThemeData) {
+	g.theme = theme
+}
+
+// SetChatEnabled shows or hides the chat widget
+func (g *Generator) SetChatEnabled(enabled bool) {
+	g.chatEnabled = enabled
 }
 
 // Generate creates a lightweight HTML shell that loads content dynamically
-func (g *Generator) Generate(doc *pdf.Document) error {
+func (g *Generator) Generate(doc *document.Document) error {
 	// Ensure output directory exists
 	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Prepare minimal page data (only title for initial load)
+	// Navigation is pre-rendered and replaced by the script once content.json loads
+	sections, toc := convertSections(doc.Sections)
+	anchors := make(map[string]string, len(sections))
+	for _, section := range sections {
+		anchors[section.ID] = "#" + section.ID
+	}
+
 	pageData := PageData{
-		Title: doc.Title,
+		Title:       doc.Title,
+		DocTitle:    doc.Title,
+		Lang:        doc.Language,
+		DocsBase:    g.docsBase,
+		StaticIndex: g.staticIndex,
+		ChatEnabled: g.chatEnabled,
+		Nav:         navItems(toc, anchors, ""),
+		Theme:       g.theme,
+		Meta:        newPageMeta(len(sections)),
+	}
+	if pageData.Lang == "" {
+		pageData.Lang = "en"
 	}
 
-	// Parse template
-	tmpl, err := template.ParseFiles(g.templatePath)
+	// Parse the page with the layout and partials
+	tmpl, err := parseTemplates(g.files, g.templatePath)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
 	}
 
 	// Generate lightweight index.html
 	outputPath := filepath.Join(g.outputDir, "index.html")
-	outputFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
-	}
-	defer outputFile.Close()
-
-	if err := tmpl.Execute(outputFile, pageData); err != nil {
+	if err := executePage(tmpl, g.templatePath, outputPath, pageData); err != nil {
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
 
@@ -63,6 +132,15 @@ func (g *Generator) Generate(doc *pdf.Document) error {
 }
 
 // GenerateAll generates all necessary HTML files
-func (g *Generator) GenerateAll(doc *pdf.Document) error {
+func (g *Generator) GenerateAll(doc *document.Document) error {
 	return g.Generate(doc)
 }
+
+// newPageMeta describes the current build
+func newPageMeta(sections int) PageMeta {
+	return PageMeta{
+		Generator: "docTrainerGO",
+		Generated: time.Now().Format("2006-01-02"),
+		Sections:  sections,
+	}
+}
@@ -0,0 +1,210 @@
+package generator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// imageSrcRegex matches stored images referenced by pre-rendered HTML
+	imageSrcRegex = regexp.MustCompile(`src="images/([^"]+)"`)
+	// closingTagRegex matches closing script and style tags, which would end
+	// an inlined asset early
+	closingTagRegex = regexp.MustCompile(`(?i)</(script|style)`)
+)
+
+// OfflineExporter writes the generated documentation as one self-contained
+// HTML file that works from file:// without a server
+type OfflineExporter struct {
+	files        fs.FS // templates and static assets
+	templatePath string
+	outputDir    string // output directory of the build being exported
+	theme        ThemeData
+}
+
+// NewOfflineExporter creates a new single-file HTML exporter
+func NewOfflineExporter(files fs.FS, templatePath, outputDir string) *OfflineExporter {
+	return &OfflineExporter{
+		files:        files,
+		templatePath: templatePath,
+		outputDir:    outputDir,
+	}
+}
+
+// SetTheme sets the branding options passed to the templates
+func (oe *OfflineExporter) SetTheme(theme ThemeData) {
+	oe.theme = theme
+}
+
+// OfflineAssets holds the stylesheet, scripts and data inlined into an
+// offline export instead of being linked
+type OfflineAssets struct {
+	Style   template.CSS
+	Scripts []template.JS
+	Data    template.JS
+}
+
+// offlineData is what the script reads instead of fetching content.json,
+// the search index and images
+type offlineData struct {
+	Content json.RawMessage   `json:"content"`
+	Search  json.RawMessage   `json:"search"`
+	Images  map[string]string `json:"images"` // stored image name -> data URI
+}
+
+// Export writes the single-file documentation to path
+func (oe *OfflineExporter) Export(path string) error {
+	// Read the generated content and search index
+	contentJSON, err := os.ReadFile(filepath.Join(oe.outputDir, "data", "content.json"))
+	if err != nil {
+		return fmt.Errorf("failed to read content.json: %w", err)
+	}
+	var content ContentData
+	if err := json.Unmarshal(contentJSON, &content); err != nil {
+		return fmt.Errorf("failed to parse content.json: %w", err)
+	}
+	searchJSON, err := os.ReadFile(filepath.Join(oe.outputDir, "search-index.json"))
+	if err != nil {
+		return fmt.Errorf("failed to read search index: %w", err)
+	}
+
+	// Embed every referenced image, including a local logo
+	theme := oe.theme
+	names := imageNames(content)
+	logo := strings.TrimPrefix(theme.Logo, "images/")
+	if logo != theme.Logo {
+		names = append(names, logo)
+	}
+	images, err := oe.dataURIs(names)
+	if err != nil {
+		return err
+	}
+	if uri, ok := images[logo]; ok {
+		theme.Logo = uri
+	}
+
+	// Inline the stylesheet and scripts
+	style, err := fs.ReadFile(oe.files, "static/style.css")
+	if err != nil {
+		return fmt.Errorf("failed to read style.css: %w", err)
+	}
+	var scripts []template.JS
+	for _, name := range []string{"static/fuse.min.js", "static/script.js"} {
+		script, err := fs.ReadFile(oe.files, name)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		scripts = append(scripts, template.JS(inlineSafe(string(script))))
+	}
+	data, err := json.Marshal(offlineData{
+		Content: contentJSON,
+		Search:  searchJSON,
+		Images:  images,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode offline data: %w", err)
+	}
+
+	// Pre-rendered navigation with in-page anchors
+	anchors := make(map[string]string, len(content.Sections))
+	for _, section := range content.Sections {
+		anchors[section.ID] = "#" + section.ID
+	}
+
+	pageData := PageData{
+		Title:    content.Title,
+		DocTitle: content.Title,
+		Lang:     content.Language,
+		DocsBase: ".",
+		Nav:      navItems(content.TOC, anchors, ""),
+		Theme:    theme,
+		Meta:     newPageMeta(len(content.Sections)),
+		Offline: &OfflineAssets{
+			Style:   template.CSS(inlineSafe(string(style))),
+			Scripts: scripts,
+			Data:    template.JS("window.offlineData = " + inlineSafe(string(data)) + ";"),
+		},
+	}
+	if pageData.Lang == "" {
+		pageData.Lang = "en"
+	}
+
+	// Parse the page with the layout and partials
+	tmpl, err := parseTemplates(oe.files, oe.templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create export directory: %w", err)
+		}
+	}
+	if err := executePage(tmpl, oe.templatePath, path, pageData); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat export: %w", err)
+	}
+	fmt.Printf("Generated: %s (%d KB, self-contained)\n", path, info.Size()/1024)
+	return nil
+}
+
+// imageNames lists the stored images a document references, sorted and
+// without duplicates
+func imageNames(content ContentData) []string {
+	seen := make(map[string]bool)
+	for _, image := range content.Images {
+		seen[image.Name] = true
+	}
+	for _, section := range content.Sections {
+		for _, image := range section.Images {
+			seen[image.Src] = true
+		}
+		for _, match := range imageSrcRegex.FindAllStringSubmatch(section.HTML, -1) {
+			seen[match[1]] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		if name != "" && !isAbsoluteURL(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dataURIs reads stored images and encodes them as base64 data URIs
+func (oe *OfflineExporter) dataURIs(names []string) (map[string]string, error) {
+	uris := make(map[string]string, len(names))
+	for _, name := range names {
+		image, err := os.ReadFile(filepath.Join(oe.outputDir, "images", filepath.FromSlash(name)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image %s: %w", name, err)
+		}
+		mediaType := mime.TypeByExtension(filepath.Ext(name))
+		if mediaType == "" {
+			mediaType = "application/octet-stream"
+		}
+		uris[name] = "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(image)
+	}
+	return uris, nil
+}
+
+// inlineSafe keeps inlined CSS and JavaScript from closing their element
+func inlineSafe(source string) string {
+	return closingTagRegex.ReplaceAllString(source, `<\/$1`)
+}
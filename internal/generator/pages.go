@@ -0,0 +1,313 @@
+package generator
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"docTrainerGO/internal/document"
+)
+
+var (
+	// sectionLinkRegex matches in-page links to sections in rendered HTML
+	sectionLinkRegex = regexp.MustCompile(`href="#(section-\d+)"`)
+	// slugRegex matches runs of characters not allowed in page file names
+	slugRegex = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// PageGenerator renders a complete static HTML page per chapter or section,
+// readable without JavaScript and by crawlers
+type PageGenerator struct {
+	files        fs.FS // templates and static assets
+	templatePath string
+	outputDir    string
+	baseURL      string // public URL of the output directory
+	pageLevel    int    // deepest heading level with its own page; 0 means every section
+	theme        ThemeData
+	chatEnabled  bool
+}
+
+// NewPageGenerator creates a new static page generator
+func NewPageGenerator(files fs.FS, templatePath, outputDir, baseURL string, pageLevel int) *PageGenerator {
+	if baseURL == "" {
+		baseURL = "/docs"
+	}
+	return &PageGenerator{
+		files:        files,
+		templatePath: templatePath,
+		outputDir:    outputDir,
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		pageLevel:    pageLevel,
+	}
+}
+
+// SetTheme sets the branding options passed to the templates
+func (pg *PageGenerator) SetTheme(theme ThemeData) {
+	pg.theme = theme
+}
+
+// SetChatEnabled shows or hides the chat widget
+func (pg *PageGenerator) SetChatEnabled(enabled bool) {
+	pg.chatEnabled = enabled
+}
+
+// StaticPageData represents data passed to the static page template
+type StaticPageData struct {
+	PageData
+	Description string
+	Canonical   string
+	Breadcrumbs []PageLink
+	Sections    []PageSection
+	Contents    []*NavItem // table of contents shown on the index page
+	Prev        *PageLink
+	Next        *PageLink
+}
+
+// NavItem is an entry of the static navigation tree
+type NavItem struct {
+	URL      string
+	Heading  string
+	Level    int
+	Active   bool
+	Children []*NavItem
+}
+
+// PageLink links to another static page
+type PageLink struct {
+	URL     string
+	Heading string
+}
+
+// PageSection is a section rendered on a static page
+type PageSection struct {
+	ID      string
+	Level   int
+	Heading string
+	HTML    template.HTML
+	Images  []ImageRef // images listed after the section
+}
+
+// Generate writes the static pages to <output>/pages/, with an index page
+// and pages.json mapping section IDs to their page URLs
+func (pg *PageGenerator) Generate(doc *document.Document) error {
+	pagesDir := filepath.Join(pg.outputDir, "pages")
+	if err := os.MkdirAll(pagesDir, 0755); err != nil {
+		return fmt.Errorf("failed to create pages directory: %w", err)
+	}
+
+	tmpl, err := parseTemplates(pg.files, pg.templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	lang := doc.Language
+	if lang == "" {
+		lang = "en"
+	}
+
+	sections, toc := convertSections(doc.Sections)
+	owners, pages := pg.assignPages(sections)
+
+	// File names and section URLs (relative to the pages directory)
+	files := pageFiles(sections, pages)
+	urls := make(map[string]string, len(sections))
+	for i, section := range sections {
+		urls[section.ID] = files[owners[i]]
+		if owners[i] != i {
+			urls[section.ID] += "#" + section.ID
+		}
+	}
+
+	// Data shared by all pages; the output directory is the parent of pages/
+	page := func(title, currentID string) PageData {
+		return PageData{
+			Title:       title,
+			DocTitle:    doc.Title,
+			Lang:        lang,
+			HomeURL:     "index.html",
+			DocsBase:    "..",
+			Static:      true,
+			ChatEnabled: pg.chatEnabled,
+			Nav:         navItems(toc, urls, currentID),
+			Theme:       pg.theme,
+			Meta:        newPageMeta(len(sections)),
+		}
+	}
+
+	// Index page with the full table of contents
+	index := StaticPageData{
+		PageData:  page(doc.Title, ""),
+		Canonical: pg.baseURL + "/pages/index.html",
+		Contents:  navItems(toc, urls, ""),
+	}
+	if len(pages) > 0 {
+		index.Next = &PageLink{URL: files[pages[0]], Heading: sections[pages[0]].Heading}
+	}
+	if err := pg.render(tmpl, filepath.Join(pagesDir, "index.html"), index); err != nil {
+		return err
+	}
+
+	// One page per owner section, holding the sections nested below it
+	for n, owner := range pages {
+		section := sections[owner]
+		data := StaticPageData{
+			PageData:    page(section.Heading, section.ID),
+			Description: description(section.Content),
+			Canonical:   pg.baseURL + "/pages/" + files[owner],
+			Prev:        &PageLink{URL: "index.html", Heading: doc.Title},
+		}
+		for _, crumb := range section.Breadcrumbs[:len(section.Breadcrumbs)-1] {
+			data.Breadcrumbs = append(data.Breadcrumbs, PageLink{URL: urls[crumb.ID], Heading: crumb.Heading})
+		}
+		if n > 0 {
+			data.Prev = &PageLink{URL: files[pages[n-1]], Heading: sections[pages[n-1]].Heading}
+		}
+		if n+1 < len(pages) {
+			data.Next = &PageLink{URL: files[pages[n+1]], Heading: sections[pages[n+1]].Heading}
+		}
+		for i := range sections {
+			if owners[i] == owner {
+				data.Sections = append(data.Sections, pageSection(sections[i], urls))
+			}
+		}
+
+		if err := pg.render(tmpl, filepath.Join(pagesDir, files[owner]), data); err != nil {
+			return err
+		}
+	}
+
+	// The optional script uses the map to open search results
+	if err := saveJSON(filepath.Join(pagesDir, "pages.json"), urls); err != nil {
+		return fmt.Errorf("failed to save pages.json: %w", err)
+	}
+
+	fmt.Printf("Generated: %d static pages in %s\n", len(pages), pagesDir)
+	return nil
+}
+
+// assignPages decides which page every section is rendered on. Sections up
+// to the page level get their own page; deeper ones join their nearest
+// ancestor's page. It returns the owning section of each section and the
+// owners in reading order.
+func (pg *PageGenerator) assignPages(sections []SectionData) ([]int, []int) {
+	index := make(map[string]int, len(sections))
+	owners := make([]int, len(sections))
+	var pages []int
+
+	for i, section := range sections {
+		index[section.ID] = i
+		parent, hasParent := index[section.ParentID]
+		if pg.pageLevel > 0 && section.Level > pg.pageLevel && hasParent {
+			owners[i] = owners[parent]
+			continue
+		}
+		owners[i] = i
+		pages = append(pages, i)
+	}
+	return owners, pages
+}
+
+// pageFiles names the page files after their headings, keeping the names
+// unique; "index" is reserved for the table of contents
+func pageFiles(sections []SectionData, pages []int) map[int]string {
+	files := make(map[int]string, len(pages))
+	used := map[string]bool{"index": true}
+
+	for _, owner := range pages {
+		slug := strings.Trim(slugRegex.ReplaceAllString(strings.ToLower(sections[owner].Heading), "-"), "-")
+		if slug == "" {
+			slug = sections[owner].ID
+		}
+		name := slug
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s-%d", slug, n)
+		}
+		used[name] = true
+		files[owner] = name + ".html"
+	}
+	return files
+}
+
+// navItems converts the table of contents to navigation links, marking the
+// entry of the current page as active
+func navItems(nodes []*TOCNode, urls map[string]string, currentID string) []*NavItem {
+	items := make([]*NavItem, 0, len(nodes))
+	for _, node := range nodes {
+		items = append(items, &NavItem{
+			URL:      urls[node.ID],
+			Heading:  node.Heading,
+			Level:    node.Level,
+			Active:   node.ID == currentID,
+			Children: navItems(node.Children, urls, currentID),
+		})
+	}
+	return items
+}
+
+// pageSection prepares a section for a static page, pointing section links
+// at their pages and image paths at the output directory
+func pageSection(section SectionData, urls map[string]string) PageSection {
+	html := section.HTML
+	if html == "" {
+		html = paragraphs(section.Content)
+	}
+	html = sectionLinkRegex.ReplaceAllStringFunc(html, func(match string) string {
+		id := sectionLinkRegex.FindStringSubmatch(match)[1]
+		if url, ok := urls[id]; ok {
+			return fmt.Sprintf(`href="%s"`, url)
+		}
+		return match
+	})
+	html = strings.ReplaceAll(html, `src="images/`, `src="../images/`)
+
+	// Images without an inline position (e.g. from PDFs) follow the section
+	var images []ImageRef
+	for _, image := range section.Images {
+		if image.Position < 0 {
+			images = append(images, image)
+		}
+	}
+
+	return PageSection{
+		ID:      section.ID,
+		Level:   section.Level,
+		Heading: section.Heading,
+		HTML:    template.HTML(html),
+		Images:  images,
+	}
+}
+
+// paragraphs renders plain section text as HTML paragraphs
+func paragraphs(content string) string {
+	var b strings.Builder
+	for _, para := range strings.Split(content, "\n\n") {
+		if para = strings.TrimSpace(para); para != "" {
+			b.WriteString("<p>" + template.HTMLEscapeString(para) + "</p>\n")
+		}
+	}
+	return b.String()
+}
+
+// description returns the start of a section's text for the meta description,
+// without Markdown emphasis and code markers
+func description(content string) string {
+	content = strings.NewReplacer("**", "", "__", "", "`", "").Replace(content)
+	text := strings.Join(strings.Fields(content), " ")
+	if len([]rune(text)) <= 160 {
+		return text
+	}
+	runes := []rune(text)[:157]
+	return strings.TrimSpace(string(runes)) + "..."
+}
+
+// render executes the template into a page file
+func (pg *PageGenerator) render(tmpl *template.Template, path string, data StaticPageData) error {
+	if err := executePage(tmpl, pg.templatePath, path, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+)
+
+// colorRegex matches the CSS color values accepted for theme colors:
+// names, hex colors and functions such as rgb(...) or hsl(...)
+var colorRegex = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|[a-zA-Z]+|[a-z]+\([0-9a-zA-Z.,%/\s-]+\))$`)
+
+// ThemeData carries the branding options of the theme configuration to the
+// templates
+type ThemeData struct {
+	Logo        string       // logo path relative to the output directory, or an absolute URL
+	Style       template.CSS // CSS variables for the configured colors
+	FooterText  string
+	FooterLinks []config.Link
+}
+
+// LoadTheme prepares the branding options. A local logo file is copied to
+// the images directory of the output.
+func LoadTheme(theme config.Theme, outputDir string) (ThemeData, error) {
+	data := ThemeData{
+		FooterText:  theme.FooterText,
+		FooterLinks: theme.FooterLinks,
+	}
+
+	// Logo
+	if theme.Logo != "" {
+		if isAbsoluteURL(theme.Logo) {
+			data.Logo = theme.Logo
+		} else {
+			logo, err := os.ReadFile(theme.Logo)
+			if err != nil {
+				return data, fmt.Errorf("failed to read logo: %w", err)
+			}
+			store := document.NewImageStore(filepath.Join(outputDir, "images"))
+			name, err := store.Save(logo, filepath.Ext(theme.Logo), theme.Logo)
+			if err != nil {
+				return data, fmt.Errorf("failed to copy logo: %w", err)
+			}
+			data.Logo = "images/" + name
+		}
+	}
+
+	// Colors become overrides of the style.css variables
+	colors := []struct {
+		variable string
+		value    string
+	}{
+		{"--primary-color", theme.Colors.Primary},
+		{"--primary-hover", theme.Colors.PrimaryHover},
+		{"--background", theme.Colors.Background},
+		{"--surface", theme.Colors.Surface},
+		{"--border", theme.Colors.Border},
+		{"--text-primary", theme.Colors.Text},
+		{"--text-secondary", theme.Colors.TextSecondary},
+	}
+	var rules []string
+	for _, color := range colors {
+		if color.value == "" {
+			continue
+		}
+		value := strings.TrimSpace(color.value)
+		if !colorRegex.MatchString(value) {
+			return data, fmt.Errorf("invalid theme color for %s: %q", color.variable, color.value)
+		}
+		rules = append(rules, fmt.Sprintf("%s: %s;", color.variable, value))
+	}
+	if len(rules) > 0 {
+		data.Style = template.CSS(":root { " + strings.Join(rules, " ") + " }")
+	}
+
+	return data, nil
+}
+
+// parseTemplates parses a page template together with the layout and the
+// partials it is built from. Themes can override any of the files.
+func parseTemplates(files fs.FS, page string) (*template.Template, error) {
+	tmpl, err := template.ParseFS(files, "templates/layout.html", "templates/partials/*.html")
+	if err != nil {
+		return nil, err
+	}
+	// The page is parsed last so its blocks replace the layout defaults
+	return tmpl.ParseFS(files, page)
+}
+
+// executePage renders a page template; its name is the file's base name
+func executePage(tmpl *template.Template, page, outputPath string, data interface{}) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer file.Close()
+
+	return tmpl.ExecuteTemplate(file, path.Base(page), data)
+}
+
+// isAbsoluteURL reports whether a link is a full or root-relative URL
+func isAbsoluteURL(link string) bool {
+	return strings.Contains(link, "://") || strings.HasPrefix(link, "/") || strings.HasPrefix(link, "data:")
+}
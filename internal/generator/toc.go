@@ -0,0 +1,74 @@
+package generator
+
+// TOCNode represents an entry in the nested table of contents
+type TOCNode struct {
+	ID       string     `json:"id"`
+	Level    int        `json:"level"`
+	Heading  string     `json:"heading"`
+	Children []*TOCNode `json:"children"`
+}
+
+// Breadcrumb represents one step in the path from a chapter down to a section
+type Breadcrumb struct {
+	ID      string `json:"id"`
+	Heading string `json:"heading"`
+}
+
+// buildTOC nests the flat section list by heading level and fills in the
+// parent, child, breadcrumb and previous/next links of every section
+func buildTOC(sections []SectionData) []*TOCNode {
+	roots := make([]*TOCNode, 0)
+
+	// Stack of open ancestors (indexes into sections and their TOC nodes)
+	var stack []int
+	nodes := make([]*TOCNode, len(sections))
+
+	for i := range sections {
+		section := &sections[i]
+		node := &TOCNode{
+			ID:       section.ID,
+			Level:    section.Level,
+			Heading:  section.Heading,
+			Children: make([]*TOCNode, 0),
+		}
+		nodes[i] = node
+
+		// Close ancestors at the same or a deeper level
+		for len(stack) > 0 && sections[stack[len(stack)-1]].Level >= section.Level {
+			stack = stack[:len(stack)-1]
+		}
+
+		// Attach to parent or make a new root
+		if len(stack) > 0 {
+			parentIdx := stack[len(stack)-1]
+			section.ParentID = sections[parentIdx].ID
+			sections[parentIdx].ChildIDs = append(sections[parentIdx].ChildIDs, section.ID)
+			nodes[parentIdx].Children = append(nodes[parentIdx].Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+
+		// Breadcrumbs run from the outermost ancestor down to the section itself
+		section.Breadcrumbs = make([]Breadcrumb, 0, len(stack)+1)
+		for _, idx := range stack {
+			section.Breadcrumbs = append(section.Breadcrumbs, Breadcrumb{
+				ID:      sections[idx].ID,
+				Heading: sections[idx].Heading,
+			})
+		}
+		section.Breadcrumbs = append(section.Breadcrumbs, Breadcrumb{
+			ID:      section.ID,
+			Heading: section.Heading,
+		})
+
+		// Previous/next follow reading order
+		if i > 0 {
+			section.PrevID = sections[i-1].ID
+			sections[i-1].NextID = section.ID
+		}
+
+		stack = append(stack, i)
+	}
+
+	return roots
+}
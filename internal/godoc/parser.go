@@ -0,0 +1,424 @@
+package godoc
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/doc/comment"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/md"
+)
+
+// Parser builds API reference sections from the Go packages of a module
+type Parser struct {
+	sectionID int
+	symbols   map[string]string // "importpath", "importpath.Name" and "importpath.Recv.Name" -> section ID
+}
+
+// NewParser creates a new Go documentation parser
+func NewParser() *Parser {
+	return &Parser{
+		sectionID: 0,
+		symbols:   make(map[string]string),
+	}
+}
+
+// goPackage is a parsed package with its documentation
+type goPackage struct {
+	doc     *doc.Package
+	fset    *token.FileSet
+	dir     string
+	heading string // section heading, "package name" qualified if ambiguous
+}
+
+// ParseModule documents every package below dir. The import paths are
+// derived from the module path in dir/go.mod.
+func (p *Parser) ParseModule(dir string) (*document.Document, error) {
+	modulePath, err := readModulePath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []*goPackage
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+
+		// The go tool ignores these directories too
+		name := info.Name()
+		if path != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+		// Nested modules are documented on their own
+		if path != dir {
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		importPath := modulePath
+		if rel != "." {
+			importPath += "/" + filepath.ToSlash(rel)
+		}
+
+		pkg, err := loadPackage(path, importPath)
+		if err != nil {
+			return err
+		}
+		if pkg != nil {
+			// Qualify the heading in case another package has the same name
+			pkg.heading = filepath.ToSlash(rel)
+			if rel == "." {
+				pkg.heading = modulePath
+			}
+			packages = append(packages, pkg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("no Go packages found in %s", dir)
+	}
+	fmt.Printf("Found %d Go packages\n", len(packages))
+
+	// Packages sharing a name, such as several commands named main, keep
+	// their directory in the heading
+	names := make(map[string]int)
+	for _, pkg := range packages {
+		names[pkg.doc.Name]++
+	}
+	for _, pkg := range packages {
+		if names[pkg.doc.Name] > 1 {
+			pkg.heading = fmt.Sprintf("package %s (%s)", pkg.doc.Name, pkg.heading)
+		} else {
+			pkg.heading = "package " + pkg.doc.Name
+		}
+	}
+
+	// Assign section IDs first so doc links can point at any symbol
+	for _, pkg := range packages {
+		p.assignIDs(pkg.doc)
+	}
+
+	doc := &document.Document{
+		Title:    modulePath,
+		Sections: make([]document.Section, 0),
+	}
+	for _, pkg := range packages {
+		doc.Sections = append(doc.Sections, p.packageSections(pkg)...)
+	}
+
+	return doc, nil
+}
+
+// readModulePath returns the module path declared in dir/go.mod
+func readModulePath(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	if path := modfile.ModulePath(data); path != "" {
+		return path, nil
+	}
+	return "", fmt.Errorf("no module directive in %s", filepath.Join(dir, "go.mod"))
+}
+
+// loadPackage parses the package in dir for the default build context,
+// including its test files for examples. It returns nil if dir holds no
+// Go package or one go/build cannot load.
+func loadPackage(dir, importPath string) (*goPackage, error) {
+	bp, err := build.ImportDir(dir, build.ImportComment)
+	if err != nil {
+		if _, ok := err.(*build.NoGoError); !ok {
+			fmt.Printf("  Warning: skipping %s: %v\n", importPath, err)
+		}
+		return nil, nil
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	names := append(append(append([]string{}, bp.GoFiles...), bp.TestGoFiles...), bp.XTestGoFiles...)
+	for _, name := range names {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, name), err)
+		}
+		files = append(files, f)
+	}
+
+	pkg, err := doc.NewFromFiles(fset, files, importPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read documentation of %s: %w", importPath, err)
+	}
+
+	return &goPackage{doc: pkg, fset: fset, dir: dir}, nil
+}
+
+// nextID returns a new section ID
+func (p *Parser) nextID() string {
+	p.sectionID++
+	return fmt.Sprintf("section-%d", p.sectionID)
+}
+
+// assignIDs gives the package, its functions, types and methods section IDs
+func (p *Parser) assignIDs(pkg *doc.Package) {
+	p.symbols[pkg.ImportPath] = p.nextID()
+	for _, f := range pkg.Funcs {
+		p.symbols[pkg.ImportPath+"."+f.Name] = p.nextID()
+	}
+	for _, t := range pkg.Types {
+		p.symbols[pkg.ImportPath+"."+t.Name] = p.nextID()
+		for _, f := range t.Funcs {
+			p.symbols[pkg.ImportPath+"."+f.Name] = p.nextID()
+		}
+		for _, m := range t.Methods {
+			p.symbols[pkg.ImportPath+"."+t.Name+"."+m.Name] = p.nextID()
+		}
+	}
+}
+
+// packageSections returns the sections of one package: the package itself,
+// then its functions and types, with constructors and methods below their type
+func (p *Parser) packageSections(pkg *goPackage) []document.Section {
+	d := pkg.doc
+	sections := make([]document.Section, 0)
+
+	add := func(id string, level int, heading, source string, body []string) {
+		section := document.Section{
+			ID:       id,
+			Level:    level,
+			Heading:  heading,
+			Source:   source,
+			Markdown: strings.TrimSpace(strings.Join(body, "\n")),
+		}
+		md.FinishSection(&section)
+		sections = append(sections, section)
+	}
+
+	// Package overview
+	body := []string{p.renderDoc(d, d.Doc), "", "```go", fmt.Sprintf("import %q", d.ImportPath), "```"}
+	body = append(body, p.valueBlocks(pkg, "Constants", d.Consts)...)
+	body = append(body, p.valueBlocks(pkg, "Variables", d.Vars)...)
+	body = append(body, p.examples(pkg, d.Examples)...)
+	add(p.symbols[d.ImportPath], 1, pkg.heading, pkg.dir, body)
+
+	for _, f := range d.Funcs {
+		add(p.symbols[d.ImportPath+"."+f.Name], 2, "func "+f.Name, p.source(pkg, f.Decl), p.funcBody(pkg, f))
+	}
+
+	for _, t := range d.Types {
+		body := []string{"```go", p.formatNode(pkg, t.Decl), "```", "", p.renderDoc(d, t.Doc)}
+		body = append(body, p.valueBlocks(pkg, "Constants", t.Consts)...)
+		body = append(body, p.valueBlocks(pkg, "Variables", t.Vars)...)
+		body = append(body, p.examples(pkg, t.Examples)...)
+		add(p.symbols[d.ImportPath+"."+t.Name], 2, "type "+t.Name, p.source(pkg, t.Decl), body)
+
+		for _, f := range t.Funcs {
+			add(p.symbols[d.ImportPath+"."+f.Name], 3, "func "+f.Name, p.source(pkg, f.Decl), p.funcBody(pkg, f))
+		}
+		for _, m := range t.Methods {
+			heading := fmt.Sprintf("func (%s) %s", m.Recv, m.Name)
+			add(p.symbols[d.ImportPath+"."+t.Name+"."+m.Name], 3, heading, p.source(pkg, m.Decl), p.funcBody(pkg, m))
+		}
+	}
+
+	return sections
+}
+
+// funcBody returns the Markdown body of a function or method section
+func (p *Parser) funcBody(pkg *goPackage, f *doc.Func) []string {
+	body := []string{"```go", p.formatNode(pkg, f.Decl), "```", "", p.renderDoc(pkg.doc, f.Doc)}
+	return append(body, p.examples(pkg, f.Examples)...)
+}
+
+// valueBlocks returns const or var declarations with their documentation
+func (p *Parser) valueBlocks(pkg *goPackage, title string, values []*doc.Value) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	lines := []string{"", "**" + title + "**"}
+	for _, v := range values {
+		lines = append(lines, "", "```go", p.formatNode(pkg, v.Decl), "```")
+		if text := p.renderDoc(pkg.doc, v.Doc); text != "" {
+			lines = append(lines, "", text)
+		}
+	}
+	return lines
+}
+
+// examples returns the examples with their expected output
+func (p *Parser) examples(pkg *goPackage, examples []*doc.Example) []string {
+	var lines []string
+	for _, ex := range examples {
+		title := "Example"
+		if ex.Suffix != "" {
+			title += " (" + ex.Suffix + ")"
+		}
+		lines = append(lines, "", "**"+title+"**")
+		if text := p.renderDoc(pkg.doc, ex.Doc); text != "" {
+			lines = append(lines, "", text)
+		}
+		lines = append(lines, "", "```go", p.exampleCode(pkg, ex), "```")
+		if ex.Output != "" {
+			lines = append(lines, "", "Output:", "", "```text", strings.TrimRight(ex.Output, "\n"), "```")
+		}
+	}
+	return lines
+}
+
+// exampleCode formats the body of an example function without its braces
+func (p *Parser) exampleCode(pkg *goPackage, ex *doc.Example) string {
+	code := p.formatNode(pkg, &printer.CommentedNode{Node: ex.Code, Comments: ex.Comments})
+
+	// Whole-file examples print as is; block examples lose the braces and
+	// one level of indentation
+	if _, ok := ex.Code.(*ast.BlockStmt); !ok {
+		return code
+	}
+	code = strings.TrimSpace(code)
+	code = strings.TrimSuffix(strings.TrimPrefix(code, "{"), "}")
+	lines := strings.Split(strings.Trim(code, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, "\t")
+	}
+	// Example output comments are shown separately
+	for len(lines) > 0 {
+		last := strings.TrimSpace(lines[len(lines)-1])
+		if !strings.HasPrefix(last, "//") || ex.Output == "" {
+			break
+		}
+		lines = lines[:len(lines)-1]
+		if strings.HasPrefix(last, "// Output:") || strings.HasPrefix(last, "// Unordered output:") {
+			break
+		}
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// formatNode prints a declaration; function bodies have already been
+// removed by go/doc
+func (p *Parser) formatNode(pkg *goPackage, node interface{}) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, pkg.fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// source returns the file a declaration was read from
+func (p *Parser) source(pkg *goPackage, node ast.Node) string {
+	if node == nil {
+		return pkg.dir
+	}
+	return pkg.fset.Position(node.Pos()).Filename
+}
+
+// renderDoc converts a doc comment to Markdown the site renderer reads.
+// Headings become bold lines, code blocks are fenced and links to other
+// documented symbols point at their sections.
+func (p *Parser) renderDoc(pkg *doc.Package, text string) string {
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	parsed := pkg.Parser().Parse(text)
+
+	var blocks []string
+	for _, block := range parsed.Content {
+		switch b := block.(type) {
+		case *comment.Heading:
+			blocks = append(blocks, "**"+p.renderText(pkg, b.Text)+"**")
+		case *comment.Paragraph:
+			blocks = append(blocks, p.renderText(pkg, b.Text))
+		case *comment.Code:
+			blocks = append(blocks, "```\n"+strings.TrimRight(b.Text, "\n")+"\n```")
+		case *comment.List:
+			var items []string
+			for _, item := range b.Items {
+				marker := "-"
+				if item.Number != "" {
+					marker = "1."
+				}
+				var parts []string
+				for _, content := range item.Content {
+					if para, ok := content.(*comment.Paragraph); ok {
+						parts = append(parts, p.renderText(pkg, para.Text))
+					}
+				}
+				items = append(items, marker+" "+strings.Join(parts, " "))
+			}
+			blocks = append(blocks, strings.Join(items, "\n"))
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// renderText converts inline doc comment text to a single Markdown line.
+// Text is escaped so characters such as * and | in comments stay literal.
+func (p *Parser) renderText(pkg *doc.Package, text []comment.Text) string {
+	var b strings.Builder
+	for _, t := range text {
+		switch t := t.(type) {
+		case comment.Plain:
+			b.WriteString(md.EscapeText(string(t)))
+		case comment.Italic:
+			b.WriteString("*" + md.EscapeText(string(t)) + "*")
+		case *comment.Link:
+			b.WriteString(fmt.Sprintf("[%s](%s)", p.renderText(pkg, t.Text), t.URL))
+		case *comment.DocLink:
+			label := p.renderText(pkg, t.Text)
+			if target := p.docLinkTarget(pkg, t); target != "" {
+				b.WriteString(fmt.Sprintf("[%s](%s)", label, target))
+			} else {
+				b.WriteString(label)
+			}
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// docLinkTarget returns the section anchor of a [Name] doc link, or the
+// pkg.go.dev page for symbols outside the module
+func (p *Parser) docLinkTarget(pkg *doc.Package, link *comment.DocLink) string {
+	importPath := link.ImportPath
+	if importPath == "" {
+		importPath = pkg.ImportPath
+	}
+
+	key := importPath
+	if link.Recv != "" {
+		key += "." + link.Recv
+	}
+	if link.Name != "" {
+		key += "." + link.Name
+	}
+	if id, ok := p.symbols[key]; ok {
+		return "#" + id
+	}
+	if link.ImportPath == "" {
+		return ""
+	}
+	return link.DefaultURL("https://pkg.go.dev")
+}
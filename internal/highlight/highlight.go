@@ -0,0 +1,273 @@
+package highlight
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSS classes emitted around highlighted tokens
+const (
+	classKeyword = "hl-kw"
+	classBuiltin = "hl-bi"
+	classString  = "hl-str"
+	classNumber  = "hl-num"
+	classComment = "hl-com"
+	classKey     = "hl-key"
+	classVar     = "hl-var"
+	classLiteral = "hl-lit"
+)
+
+// rule matches a token at the start of the remaining input. Rules without
+// an opening pattern only see the current line; rules that may span lines
+// have one, and once their opening matches but the whole token does not,
+// the rest of the input cannot close it either.
+type rule struct {
+	class   string
+	pattern *regexp.Regexp
+	open    *regexp.Regexp
+}
+
+// lineRule creates a rule whose tokens never cross a line break
+func lineRule(class, expr string) rule {
+	return rule{class: class, pattern: regexp.MustCompile(expr)}
+}
+
+// spanRule creates a rule whose tokens may span lines, such as block
+// comments and multi-line strings
+func spanRule(class, open, expr string) rule {
+	return rule{class: class, pattern: regexp.MustCompile(expr), open: regexp.MustCompile(open)}
+}
+
+// language describes how to tokenize one language
+type language struct {
+	rules    []rule
+	keywords map[string]bool
+	builtins map[string]bool
+	literals map[string]bool
+	lineKeys bool // keys only count at the start of a line (YAML)
+}
+
+// wordRegex matches identifiers, which are then checked against keyword sets
+var wordRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*`)
+
+// aliases maps fence info strings to language names
+var aliases = map[string]string{
+	"golang":     "go",
+	"yml":        "yaml",
+	"sh":         "bash",
+	"shell":      "bash",
+	"zsh":        "bash",
+	"console":    "bash",
+	"py":         "python",
+	"python3":    "python",
+	"js":         "javascript",
+	"node":       "javascript",
+	"jsonc":      "json",
+	"javascript": "javascript",
+}
+
+// languages holds the tokenizers for every supported language
+var languages = map[string]*language{
+	"go": {
+		rules: []rule{
+			lineRule(classComment, `^//[^\n]*`),
+			spanRule(classComment, `^/\*`, `^/\*[\s\S]*?\*/`),
+			lineRule(classString, "^(\"(\\\\.|[^\"\\\\\\n])*\"|'(\\\\.|[^'\\\\\\n])*')"),
+			spanRule(classString, "^`", "^`[^`]*`"),
+			lineRule(classNumber, `^(0[xX][0-9a-fA-F_]+|\d[\d_]*(\.\d+)?([eE][+-]?\d+)?)`),
+		},
+		keywords: words("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var"),
+		builtins: words("append cap close complex copy delete imag len make new panic print println real recover any bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr"),
+		literals: words("true false nil iota"),
+	},
+	"yaml": {
+		rules: []rule{
+			lineRule(classComment, `^#[^\n]*`),
+			lineRule(classKey, `^[A-Za-z_][\w.-]*(\s*:)`),
+			lineRule(classString, `^("(\\.|[^"\\\n])*"|'[^'\n]*')`),
+			lineRule(classNumber, `^-?\d+(\.\d+)?\b`),
+		},
+		literals: words("true false null yes no on off"),
+		lineKeys: true,
+	},
+	"json": {
+		rules: []rule{
+			lineRule(classKey, `^"(\\.|[^"\\\n])*"(\s*:)`),
+			lineRule(classString, `^"(\\.|[^"\\\n])*"`),
+			lineRule(classNumber, `^-?\d+(\.\d+)?([eE][+-]?\d+)?`),
+		},
+		literals: words("true false null"),
+	},
+	"bash": {
+		rules: []rule{
+			lineRule(classComment, `^#[^\n]*`),
+			spanRule(classString, `^"`, `^"(\\.|[^"\\])*"`),
+			spanRule(classString, `^'`, `^'[^']*'`),
+			lineRule(classVar, `^\$(\{[^}\n]*\}|[A-Za-z_][A-Za-z0-9_]*|[0-9@#?*$!-])`),
+			lineRule(classNumber, `^\d+\b`),
+		},
+		keywords: words("if then else elif fi for while until do done case esac in function select return export local readonly"),
+		builtins: words("echo cd pwd ls cat grep sed awk rm mkdir cp mv curl wget git go make sudo source chmod chown touch printf read exit set unset test brew apt apt-get docker ollama npm"),
+	},
+	"python": {
+		rules: []rule{
+			lineRule(classComment, `^#[^\n]*`),
+			spanRule(classString, `^[rRbBfFuU]{0,2}"""`, `^[rRbBfFuU]{0,2}"""[\s\S]*?"""`),
+			spanRule(classString, `^[rRbBfFuU]{0,2}'''`, `^[rRbBfFuU]{0,2}'''[\s\S]*?'''`),
+			lineRule(classString, `^[rRbBfFuU]{0,2}("(\\.|[^"\\\n])*"|'(\\.|[^'\\\n])*')`),
+			lineRule(classNumber, `^(0[xX][0-9a-fA-F_]+|\d[\d_]*(\.\d+)?([eE][+-]?\d+)?j?)`),
+		},
+		keywords: words("and as assert async await break class continue def del elif else except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield match case"),
+		builtins: words("print len range open int str float list dict set tuple bool type isinstance super enumerate zip map filter sorted min max sum abs self cls"),
+		literals: words("True False None"),
+	},
+	"javascript": {
+		rules: []rule{
+			lineRule(classComment, `^//[^\n]*`),
+			spanRule(classComment, `^/\*`, `^/\*[\s\S]*?\*/`),
+			lineRule(classString, "^(\"(\\\\.|[^\"\\\\\\n])*\"|'(\\\\.|[^'\\\\\\n])*')"),
+			spanRule(classString, "^`", "^`(\\\\.|[^`\\\\])*`"),
+			lineRule(classNumber, `^(0[xX][0-9a-fA-F]+|\d+(\.\d+)?([eE][+-]?\d+)?n?)`),
+		},
+		keywords: words("async await break case catch class const continue debugger default delete do else export extends finally for from function if import in instanceof let new of return static super switch this throw try typeof var void while with yield"),
+		builtins: words("console document window JSON Math Object Array String Number Promise fetch require module setTimeout"),
+		literals: words("true false null undefined NaN Infinity"),
+	},
+}
+
+// Normalize maps a fence info string to a supported language name, or
+// returns the lowercased first word when the language is not supported
+func Normalize(info string) string {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return ""
+	}
+	lang := strings.ToLower(strings.Trim(fields[0], "{}."))
+	if alias, ok := aliases[lang]; ok {
+		return alias
+	}
+	return lang
+}
+
+// Supported reports whether lang can be highlighted
+func Supported(lang string) bool {
+	_, ok := languages[Normalize(lang)]
+	return ok
+}
+
+// Highlight returns code as escaped HTML with tokens wrapped in
+// <span class="hl-..."> elements. Unsupported languages are only escaped.
+func Highlight(lang, code string) string {
+	l, ok := languages[Normalize(lang)]
+	if !ok {
+		return html.EscapeString(code)
+	}
+
+	var b strings.Builder
+	dead := make([]bool, len(l.rules)) // span rules that can no longer match
+	lineStart := true
+	lineEnd := -1
+
+	for pos := 0; pos < len(code); {
+		// Line rules and identifiers only look at the current line
+		if lineEnd < pos {
+			lineEnd = strings.IndexByte(code[pos:], '\n')
+			if lineEnd < 0 {
+				lineEnd = len(code)
+			} else {
+				lineEnd += pos
+			}
+		}
+		rest, line := code[pos:], code[pos:lineEnd]
+
+		if token, class, n := l.next(rest, line, lineStart, dead); n > 0 {
+			if class == classKey {
+				// Key rules capture the trailing ":" separately
+				writeSpan(&b, class, token)
+				b.WriteString(html.EscapeString(rest[len(token):n]))
+			} else {
+				writeSpan(&b, class, token)
+			}
+			pos += n
+			lineStart = false
+			continue
+		}
+
+		// Identifiers are matched whole so keywords never split words
+		if word := wordRegex.FindString(line); word != "" {
+			switch {
+			case l.keywords[word]:
+				writeSpan(&b, classKeyword, word)
+			case l.literals[word]:
+				writeSpan(&b, classLiteral, word)
+			case l.builtins[word]:
+				writeSpan(&b, classBuiltin, word)
+			default:
+				b.WriteString(html.EscapeString(word))
+			}
+			pos += len(word)
+			lineStart = false
+			continue
+		}
+
+		// Plain character; indentation and list dashes keep the line start
+		r, size := utf8.DecodeRuneInString(rest)
+		b.WriteString(html.EscapeString(rest[:size]))
+		pos += size
+		lineStart = r == '\n' || (lineStart && (r == ' ' || r == '\t' || r == '-'))
+	}
+
+	return b.String()
+}
+
+// next tries every rule at the start of the input; line rules only see line.
+// It returns the token to wrap, its class and the total number of bytes
+// consumed. Span rules whose token cannot be closed are marked dead.
+func (l *language) next(rest, line string, lineStart bool, dead []bool) (string, string, int) {
+	for i, r := range l.rules {
+		if dead[i] || (r.class == classKey && l.lineKeys && !lineStart) {
+			continue
+		}
+		s := line
+		if r.open != nil {
+			if !r.open.MatchString(line) {
+				continue
+			}
+			s = rest
+		}
+		loc := r.pattern.FindStringSubmatchIndex(s)
+		if loc == nil || loc[1] == 0 {
+			if r.open != nil {
+				dead[i] = true
+			}
+			continue
+		}
+		if r.class == classKey {
+			// The last group is the ":" separator, which is not highlighted
+			sep := loc[len(loc)-2]
+			return s[:sep], r.class, loc[1]
+		}
+		return s[:loc[1]], r.class, loc[1]
+	}
+	return "", "", 0
+}
+
+// writeSpan writes an escaped token wrapped in a span with the given class
+func writeSpan(b *strings.Builder, class, token string) {
+	b.WriteString(`<span class="`)
+	b.WriteString(class)
+	b.WriteString(`">`)
+	b.WriteString(html.EscapeString(token))
+	b.WriteString(`</span>`)
+}
+
+// words builds a lookup set from a space separated list
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(list) {
+		set[w] = true
+	}
+	return set
+}
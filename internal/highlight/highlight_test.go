@@ -0,0 +1,102 @@
+package highlight
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		code string
+		want string
+	}{
+		{
+			name: "go keywords and strings",
+			lang: "go",
+			code: `func f() string { return "x" }`,
+			want: `<span class="hl-kw">func</span> f() <span class="hl-bi">string</span> { <span class="hl-kw">return</span> <span class="hl-str">&#34;x&#34;</span> }`,
+		},
+		{
+			name: "go block comment spans lines",
+			lang: "go",
+			code: "/* a\nb */ x",
+			want: "<span class=\"hl-com\">/* a\nb */</span> x",
+		},
+		{
+			name: "unterminated block comment stays plain",
+			lang: "go",
+			code: "/* a\nb",
+			want: "/* a\nb",
+		},
+		{
+			name: "line string does not cross lines",
+			lang: "javascript",
+			code: "\"a\nb\"",
+			want: "&#34;a\nb&#34;",
+		},
+		{
+			name: "yaml keys only at line start",
+			lang: "yml",
+			code: "- name: a: b",
+			want: `- <span class="hl-key">name</span>: a: b`,
+		},
+		{
+			name: "python triple quoted string",
+			lang: "py",
+			code: "x = '''a\nb'''",
+			want: "x = <span class=\"hl-str\">&#39;&#39;&#39;a\nb&#39;&#39;&#39;</span>",
+		},
+		{
+			name: "multi-byte characters",
+			lang: "bash",
+			code: "echo é→ü",
+			want: `<span class="hl-bi">echo</span> é→ü`,
+		},
+		{
+			name: "unsupported language is escaped",
+			lang: "text",
+			code: "<a>",
+			want: "&lt;a&gt;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.lang, tt.code); got != tt.want {
+				t.Errorf("Highlight(%q, %q)\n got: %s\nwant: %s", tt.lang, tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+// largeBlock returns a long Go snippet with unterminated comments and raw
+// strings, which must not make highlighting quadratic
+func largeBlock() string {
+	var b strings.Builder
+	b.WriteString("/* never closed\n` never closed\n")
+	for i := 0; i < 20000; i++ {
+		b.WriteString("x := \"é\" + 42 // trailing /*\n")
+	}
+	return b.String()
+}
+
+func TestHighlightLargeBlock(t *testing.T) {
+	code := largeBlock()
+	got := Highlight("go", code)
+	if !strings.HasPrefix(got, "/* never closed\n` never closed\n") {
+		t.Errorf("unterminated tokens were highlighted: %.60s", got)
+	}
+	if n := strings.Count(got, `<span class="hl-com">`); n != 20000 {
+		t.Errorf("got %d line comments, want 20000", n)
+	}
+}
+
+func BenchmarkHighlightLargeBlock(b *testing.B) {
+	code := largeBlock()
+	b.SetBytes(int64(len(code)))
+	for i := 0; i < b.N; i++ {
+		Highlight("go", code)
+	}
+}
@@ -0,0 +1,91 @@
+package htmldoc
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// node is an element or text node of a parsed HTML page
+type node struct {
+	tag      string // lower-case element name, empty for text nodes
+	attrs    map[string]string
+	text     string // text of a text node, entities decoded
+	children []*node
+	parent   *node
+}
+
+// attr returns the value of an attribute, or "" if it is not set
+func (n *node) attr(name string) string {
+	return n.attrs[name]
+}
+
+// hasClass reports whether the class attribute contains class
+func (n *node) hasClass(class string) bool {
+	for _, c := range strings.Fields(n.attrs["class"]) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the first element below n for which match returns true
+func (n *node) find(match func(*node) bool) *node {
+	for _, child := range n.children {
+		if child.tag == "" {
+			continue
+		}
+		if match(child) {
+			return child
+		}
+		if found := child.find(match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// textContent returns the text of n and all its descendants
+func (n *node) textContent() string {
+	if n.tag == "" {
+		return n.text
+	}
+	var b strings.Builder
+	for _, child := range n.children {
+		b.WriteString(child.textContent())
+	}
+	return b.String()
+}
+
+// parseHTML parses an HTML page into a tree with the HTML5 parsing
+// algorithm, so the result matches what a browser builds: optional end
+// tags are implied, mis-nested tags are repaired and <html>, <head> and
+// <body> always exist. Comments and the doctype are dropped.
+func parseHTML(src string) *node {
+	root := &node{tag: "#document", attrs: map[string]string{}}
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		// The parser only fails on read errors, which a string cannot cause
+		return root
+	}
+	convertChildren(doc, root)
+	return root
+}
+
+// convertChildren appends the element and text children of src to dst
+func convertChildren(src *html.Node, dst *node) {
+	for c := src.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.TextNode:
+			dst.children = append(dst.children, &node{text: c.Data, parent: dst})
+		case html.ElementNode:
+			element := &node{tag: strings.ToLower(c.Data), attrs: make(map[string]string, len(c.Attr)), parent: dst}
+			for _, a := range c.Attr {
+				element.attrs[a.Key] = a.Val
+			}
+			dst.children = append(dst.children, element)
+			convertChildren(c, element)
+		}
+	}
+}
@@ -0,0 +1,718 @@
+package htmldoc
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/highlight"
+	"docTrainerGO/internal/md"
+)
+
+// DefaultSelector picks the main content region of a page when none is
+// configured; the alternatives are tried in order
+const DefaultSelector = "main, article, [role=main], body"
+
+// Links between pages are written as placeholders ("\x01link:N\x01") and
+// resolved once every page has been parsed
+const linkMark = "\x01"
+
+var pendingLinkRegex = regexp.MustCompile(`\x01link:(\d+)\x01`)
+
+// pendingLink is a link to another page or fragment awaiting resolution
+type pendingLink struct {
+	target string // "file" or "file#id"
+	text   string // Link text
+}
+
+// skippedElements never contain documentation content
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "nav": true,
+	"form": true, "button": true, "iframe": true, "svg": true, "head": true, "title": true,
+	"footer": true,
+}
+
+// admonitionClasses are the class names that mark a callout box
+var admonitionClasses = []string{"note", "tip", "important", "warning", "caution", "danger", "info"}
+
+// Parser converts a directory of HTML pages into sections
+type Parser struct {
+	outputDir string
+	selector  selector
+	images    *document.ImageStore
+	sectionID int
+	anchors   map[string]string // "file#id" and "file" -> section ID
+	links     []pendingLink     // links between pages, resolved after parsing
+}
+
+// NewParser creates a new HTML parser. selector is a CSS selector for the
+// main content region; an empty selector uses DefaultSelector.
+func NewParser(outputDir, contentSelector string) (*Parser, error) {
+	if contentSelector == "" {
+		contentSelector = DefaultSelector
+	}
+	sel, err := parseSelector(contentSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Parser{
+		outputDir: outputDir,
+		selector:  sel,
+		images:    document.NewImageStore(filepath.Join(outputDir, "images")),
+		sectionID: 0,
+		anchors:   make(map[string]string),
+	}, nil
+}
+
+// ParseDirectory parses all .html files in a directory. An output directory
+// inside it is skipped so generated pages are not read back in.
+func (p *Parser) ParseDirectory(dir string) (*document.Document, error) {
+	var files []string
+	outputKey := fileKey(p.outputDir)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && path != dir && fileKey(path) == outputKey {
+			return filepath.SkipDir
+		}
+		ext := strings.ToLower(filepath.Ext(info.Name()))
+		if !info.IsDir() && (ext == ".html" || ext == ".htm") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no html files found in %s", dir)
+	}
+
+	fmt.Printf("Found %d html files\n", len(files))
+	return p.ParseFiles(files)
+}
+
+// ParseFiles parses HTML pages into one document
+func (p *Parser) ParseFiles(files []string) (*document.Document, error) {
+	doc := &document.Document{
+		Title:    "Documentation",
+		Sections: make([]document.Section, 0),
+	}
+
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		root := parseHTML(string(data))
+		title := pageTitle(root)
+		if i == 0 && title != "" {
+			doc.Title = title
+		}
+
+		content := p.selector.first(root)
+		if content == nil {
+			fmt.Printf("  Warning: %s: no element matches the content selector, skipping\n", file)
+			continue
+		}
+
+		c := &converter{parser: p, path: file, title: title}
+		c.convert(content)
+		doc.Sections = append(doc.Sections, c.sections...)
+	}
+
+	// Point links between pages at the sections they now live in, then
+	// render the final section content
+	for i := range doc.Sections {
+		p.resolveLinks(&doc.Sections[i])
+		md.FinishSection(&doc.Sections[i])
+	}
+
+	doc.Images = p.images.Assets()
+	return doc, nil
+}
+
+// pageTitle returns the text of the page's <title> element
+func pageTitle(root *node) string {
+	title := root.find(func(n *node) bool { return n.tag == "title" })
+	if title == nil {
+		return ""
+	}
+	return collapseSpace(title.textContent())
+}
+
+// resolveLinks replaces link placeholders with section anchors. Links to
+// pages or fragments that were not converted keep only their text.
+func (p *Parser) resolveLinks(section *document.Section) {
+	section.Markdown = pendingLinkRegex.ReplaceAllStringFunc(section.Markdown, func(match string) string {
+		idx, _ := strconv.Atoi(pendingLinkRegex.FindStringSubmatch(match)[1])
+		link := p.links[idx]
+
+		// Fragments that are not anchors fall back to their page
+		id, ok := p.anchors[link.target]
+		if !ok {
+			page, _, _ := strings.Cut(link.target, "#")
+			id, ok = p.anchors[page]
+		}
+		if !ok {
+			fmt.Printf("  Warning: %s: link target not found: %s\n", section.Source, link.target)
+			return link.text
+		}
+		return fmt.Sprintf("[%s](#%s)", link.text, id)
+	})
+}
+
+// fileKey normalizes a path for use as an anchor key
+func fileKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// converter turns the content region of one page into sections
+type converter struct {
+	parser   *Parser
+	path     string
+	title    string
+	sections []document.Section
+	current  *document.Section
+	lines    []string
+}
+
+// convert walks the content region and flushes the last section
+func (c *converter) convert(content *node) {
+	c.blocks(content, true)
+	c.flush()
+
+	// The page itself links to its first section
+	if len(c.sections) > 0 {
+		c.parser.anchors[fileKey(c.path)] = c.sections[0].ID
+	}
+}
+
+// flush stores the current section
+func (c *converter) flush() {
+	if c.current == nil {
+		// Content before the first heading goes into a section named after the page
+		if !hasContent(c.lines) {
+			return
+		}
+		c.newSection(1, c.introductionHeading())
+	}
+	c.current.Markdown = strings.TrimSpace(strings.Join(c.lines, "\n"))
+	c.sections = append(c.sections, *c.current)
+	c.current = nil
+	c.lines = nil
+}
+
+// newSection starts a section for a heading
+func (c *converter) newSection(level int, heading string) {
+	c.parser.sectionID++
+	c.current = &document.Section{
+		ID:      fmt.Sprintf("section-%d", c.parser.sectionID),
+		Level:   level,
+		Heading: heading,
+		Source:  c.path,
+	}
+}
+
+// introductionHeading names the section holding content before the first heading
+func (c *converter) introductionHeading() string {
+	if c.title != "" {
+		return c.title
+	}
+	base := filepath.Base(c.path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// hasContent reports whether any line holds text
+func hasContent(lines []string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// registerAnchor maps an element ID to the section it appears in
+func (c *converter) registerAnchor(n *node) {
+	id := n.attr("id")
+	if id == "" {
+		id = n.attr("name")
+	}
+	if id == "" || c.current == nil {
+		return
+	}
+	key := fileKey(c.path) + "#" + id
+	if _, exists := c.parser.anchors[key]; !exists {
+		c.parser.anchors[key] = c.current.ID
+	}
+}
+
+// blocks converts the children of n to Markdown lines. At the top level
+// headings start new sections; nested headings become bold paragraphs.
+func (c *converter) blocks(n *node, topLevel bool) []string {
+	var lines []string
+	var inline []*node
+
+	flushInline := func() {
+		if text := c.inlineNodes(inline); text != "" {
+			lines = append(lines, "", text)
+		}
+		inline = nil
+	}
+
+	for _, child := range n.children {
+		if child.tag == "" || isInlineElement(child.tag) {
+			inline = append(inline, child)
+			continue
+		}
+		flushInline()
+		if skippedElements[child.tag] {
+			continue
+		}
+
+		switch child.tag {
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			heading := collapseSpace(child.textContent())
+			if heading == "" {
+				continue
+			}
+			if !topLevel {
+				lines = append(lines, "", "**"+md.EscapeText(heading)+"**")
+				continue
+			}
+			// Close the running section and start a new one
+			c.lines = append(c.lines, lines...)
+			lines = nil
+			c.flush()
+			c.newSection(int(child.tag[1]-'0'), heading)
+			c.registerAnchor(child)
+
+		case "p":
+			c.registerAnchor(child)
+			if text := c.inlineNodes(child.children); text != "" {
+				lines = append(lines, "", text)
+			}
+
+		case "ul", "ol":
+			lines = append(lines, "")
+			lines = append(lines, c.list(child, 0)...)
+
+		case "pre":
+			lines = append(lines, "", c.codeBlock(child))
+
+		case "blockquote":
+			lines = append(lines, "")
+			for _, line := range c.blocks(child, false) {
+				if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+					continue
+				}
+				lines = append(lines, strings.TrimRight("> "+line, " "))
+			}
+
+		case "table":
+			if table := c.table(child); table != "" {
+				lines = append(lines, "", table)
+			}
+
+		case "hr":
+			lines = append(lines, "", "---")
+
+		case "dl":
+			for _, item := range child.children {
+				switch item.tag {
+				case "dt":
+					lines = append(lines, "", "**"+c.inlineNodes(item.children)+"**")
+				case "dd":
+					lines = append(lines, c.blocks(item, false)...)
+				}
+			}
+
+		case "figure":
+			lines = append(lines, "", c.figure(child))
+
+		default:
+			c.registerAnchor(child)
+			if kind := admonitionKind(child); kind != "" {
+				lines = append(lines, "", c.admonition(child, kind))
+				continue
+			}
+			// Containers such as div, section and article; at the top level
+			// the lines so far go first so headings inside split in order
+			if topLevel {
+				c.lines = append(c.lines, lines...)
+				lines = nil
+			}
+			lines = append(lines, c.blocks(child, topLevel)...)
+		}
+	}
+	flushInline()
+
+	if topLevel {
+		c.lines = append(c.lines, lines...)
+		return nil
+	}
+	return lines
+}
+
+// isInlineElement reports whether an element is part of running text
+func isInlineElement(tag string) bool {
+	switch tag {
+	case "a", "abbr", "b", "bdi", "bdo", "br", "cite", "code", "data", "dfn", "em", "i",
+		"img", "kbd", "mark", "q", "s", "samp", "small", "span", "strong", "sub", "sup",
+		"time", "tt", "u", "var", "wbr", "del", "ins":
+		return true
+	}
+	return false
+}
+
+// list converts a ul or ol element; nested lists are indented
+func (c *converter) list(n *node, depth int) []string {
+	var lines []string
+	marker := "-"
+	if n.tag == "ol" {
+		marker = "1."
+	}
+
+	for _, item := range n.children {
+		if item.tag != "li" {
+			continue
+		}
+		c.registerAnchor(item)
+
+		var text []*node
+		var nested []*node
+		for _, child := range item.children {
+			if child.tag == "ul" || child.tag == "ol" {
+				nested = append(nested, child)
+			} else {
+				text = append(text, child)
+			}
+		}
+
+		itemText := c.inlineNodes(flattenBlocks(text))
+		lines = append(lines, strings.Repeat("  ", depth)+marker+" "+itemText)
+		for _, sub := range nested {
+			lines = append(lines, c.list(sub, depth+1)...)
+		}
+	}
+	return lines
+}
+
+// flattenBlocks unwraps block elements such as <p> inside list items so
+// their text joins the item line
+func flattenBlocks(nodes []*node) []*node {
+	var flat []*node
+	for _, n := range nodes {
+		if n.tag != "" && !isInlineElement(n.tag) && !skippedElements[n.tag] {
+			flat = append(flat, &node{text: " "})
+			flat = append(flat, flattenBlocks(n.children)...)
+			continue
+		}
+		flat = append(flat, n)
+	}
+	return flat
+}
+
+// codeBlock converts a pre element to a fenced code block
+func (c *converter) codeBlock(pre *node) string {
+	lang := codeLanguage(pre)
+	if code := pre.find(func(n *node) bool { return n.tag == "code" }); code != nil && lang == "" {
+		lang = codeLanguage(code)
+	}
+	code := strings.Trim(pre.textContent(), "\n")
+	return "```" + highlight.Normalize(lang) + "\n" + code + "\n```"
+}
+
+// codeLanguage reads the language from class names such as
+// "language-go", "lang-go" and "highlight-go"
+func codeLanguage(n *node) string {
+	for _, class := range strings.Fields(n.attr("class")) {
+		for _, prefix := range []string{"language-", "lang-", "highlight-"} {
+			if lang, ok := strings.CutPrefix(class, prefix); ok {
+				return lang
+			}
+		}
+	}
+	return n.attr("data-lang")
+}
+
+// table converts a table to a Markdown pipe table; the first row is the header
+func (c *converter) table(table *node) string {
+	var rows [][]string
+	width := 0
+
+	var walk func(n *node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			switch child.tag {
+			case "thead", "tbody", "tfoot":
+				walk(child)
+			case "tr":
+				var cells []string
+				for _, cell := range child.children {
+					if cell.tag == "td" || cell.tag == "th" {
+						cells = append(cells, c.inlineNodes(flattenBlocks(cell.children)))
+					}
+				}
+				if len(cells) > width {
+					width = len(cells)
+				}
+				rows = append(rows, cells)
+			}
+		}
+	}
+	walk(table)
+
+	if len(rows) == 0 || width == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, cells := range rows {
+		for len(cells) < width {
+			cells = append(cells, "")
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// figure converts a figure; the caption becomes the image title
+func (c *converter) figure(figure *node) string {
+	caption := ""
+	if fc := figure.find(func(n *node) bool { return n.tag == "figcaption" }); fc != nil {
+		caption = collapseSpace(fc.textContent())
+	}
+
+	var parts []string
+	for _, child := range figure.children {
+		if child.tag == "figcaption" {
+			continue
+		}
+		if child.tag == "img" {
+			parts = append(parts, c.image(child, caption))
+			continue
+		}
+		if text := c.inlineNodes(flattenBlocks([]*node{child})); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// admonitionKind returns the callout type of an element marked with a
+// class such as "note" or "admonition warning", or ""
+func admonitionKind(n *node) string {
+	if n.tag != "div" && n.tag != "aside" {
+		return ""
+	}
+	for _, kind := range admonitionClasses {
+		if n.hasClass(kind) {
+			return kind
+		}
+	}
+	if n.hasClass("admonition") {
+		return "note"
+	}
+	return ""
+}
+
+// admonition converts a callout box to a fenced admonition block. A child
+// marked "admonition-title" (as Sphinx writes it) becomes the title.
+func (c *converter) admonition(n *node, kind string) string {
+	title := ""
+	var body []*node
+	for _, child := range n.children {
+		if child.hasClassSafe("admonition-title") {
+			title = collapseSpace(child.textContent())
+			continue
+		}
+		body = append(body, child)
+	}
+
+	text := c.inlineNodes(flattenBlocks(body))
+	if strings.EqualFold(title, kind) {
+		title = ""
+	}
+	return fmt.Sprintf(":::%s %s\n%s\n:::", kind, title, text)
+}
+
+// hasClassSafe is hasClass for nodes that may be text nodes
+func (n *node) hasClassSafe(class string) bool {
+	return n.tag != "" && n.hasClass(class)
+}
+
+// inlineNodes converts running text to a single line of Markdown
+func (c *converter) inlineNodes(nodes []*node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		c.inline(&b, n)
+	}
+	return collapseSpace(b.String())
+}
+
+// inline writes one inline node as Markdown. Text is escaped so it is not
+// read as markup; whitespace is kept as in the page and collapsed by
+// inlineNodes.
+func (c *converter) inline(b *strings.Builder, n *node) {
+	if n.tag == "" {
+		b.WriteString(md.EscapeText(n.text))
+		return
+	}
+	if skippedElements[n.tag] {
+		return
+	}
+	c.registerAnchor(n)
+
+	switch n.tag {
+	case "br":
+		b.WriteString(" ")
+	case "strong", "b":
+		wrapInline(b, "**", c.rawInline(n))
+	case "em", "i", "cite", "dfn", "var":
+		wrapInline(b, "*", c.rawInline(n))
+	case "code", "kbd", "samp", "tt":
+		wrapInline(b, "`", n.textContent())
+	case "img":
+		b.WriteString(c.image(n, n.attr("title")))
+	case "a":
+		wrapInline(b, "", c.link(n))
+	default:
+		for _, child := range n.children {
+			c.inline(b, child)
+		}
+	}
+}
+
+// rawInline converts the children of n without collapsing whitespace
+func (c *converter) rawInline(n *node) string {
+	var b strings.Builder
+	for _, child := range n.children {
+		c.inline(&b, child)
+	}
+	return b.String()
+}
+
+// wrapInline writes text wrapped in markers, keeping leading and trailing
+// whitespace outside them so "<b>bold </b>text" stays "**bold** text"
+func wrapInline(b *strings.Builder, marker, text string) {
+	trimmed := collapseSpace(text)
+	if trimmed == "" {
+		b.WriteString(text)
+		return
+	}
+	if strings.TrimLeft(text, " \t\r\n") != text {
+		b.WriteString(" ")
+	}
+	b.WriteString(marker + trimmed + marker)
+	if strings.TrimRight(text, " \t\r\n") != text {
+		b.WriteString(" ")
+	}
+}
+
+// image stores a local image and returns its Markdown reference. Remote
+// images are referenced by URL; missing local images keep only their alt text.
+func (c *converter) image(img *node, title string) string {
+	src := strings.TrimSpace(img.attr("src"))
+	alt := strings.NewReplacer("[", "", "]", "").Replace(collapseSpace(img.attr("alt")))
+	if src == "" {
+		return alt
+	}
+
+	reference := func(target string) string {
+		if title != "" {
+			return fmt.Sprintf("![%s](%s %q)", alt, target, strings.ReplaceAll(title, `"`, "'"))
+		}
+		return fmt.Sprintf("![%s](%s)", alt, target)
+	}
+
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme == "data" {
+		return alt
+	}
+	if u.Scheme != "" || u.Host != "" {
+		return reference(u.String())
+	}
+
+	imagePath := filepath.Join(filepath.Dir(c.path), filepath.FromSlash(u.Path))
+	data, err := os.ReadFile(imagePath)
+	if err != nil {
+		fmt.Printf("  Warning: %s: image not found: %s\n", c.path, imagePath)
+		return alt
+	}
+	name, err := c.parser.images.Save(data, filepath.Ext(imagePath), imagePath)
+	if err != nil {
+		fmt.Printf("  Warning: %s: failed to store image %s: %v\n", c.path, imagePath, err)
+		return alt
+	}
+	return reference("images/" + name)
+}
+
+// link converts an anchor element. Links to other converted pages become
+// placeholders that resolveLinks turns into section links. Whitespace around
+// the link text is kept for wrapInline.
+func (c *converter) link(a *node) string {
+	raw := c.rawInline(a)
+	text := collapseSpace(raw)
+	href := strings.TrimSpace(a.attr("href"))
+
+	// Linked images keep only the image: a link around an image reference
+	// is not valid in the Markdown the renderer reads
+	if text == "" || href == "" || strings.Contains(text, "![") {
+		return raw
+	}
+	text = linkTextReplacer.Replace(text)
+	lead, trail := "", ""
+	if strings.TrimLeft(raw, " \t\r\n") != raw {
+		lead = " "
+	}
+	if strings.TrimRight(raw, " \t\r\n") != raw {
+		trail = " "
+	}
+
+	u, err := url.Parse(href)
+	if err != nil || u.Scheme == "javascript" {
+		return raw
+	}
+	if u.Scheme != "" || u.Host != "" {
+		return fmt.Sprintf("%s[%s](%s)%s", lead, text, strings.ReplaceAll(u.String(), " ", "%20"), trail)
+	}
+
+	// Relative link: resolve against this page
+	target := fileKey(c.path)
+	if u.Path != "" {
+		target = fileKey(filepath.Join(filepath.Dir(c.path), filepath.FromSlash(u.Path)))
+	}
+	if u.Fragment != "" {
+		target += "#" + u.Fragment
+	}
+
+	c.parser.links = append(c.parser.links, pendingLink{target: target, text: text})
+	return fmt.Sprintf("%s%slink:%d%s%s", lead, linkMark, len(c.parser.links)-1, linkMark, trail)
+}
+
+// linkTextReplacer removes square brackets, escaped or not, which would end
+// link text early
+var linkTextReplacer = strings.NewReplacer(`\[`, "", `\]`, "", "[", "", "]", "")
+
+// collapseSpace collapses runs of whitespace into single spaces
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
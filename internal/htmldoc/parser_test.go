@@ -0,0 +1,130 @@
+package htmldoc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     string
+		headings []string
+		markdown []string // Markdown of each section
+	}{
+		{
+			name:     "sections split at headings",
+			page:     `<html><body><main><h1>Intro</h1><p>First <b>bold</b>.</p><h2>Next</h2><p>Second</p></main></body></html>`,
+			headings: []string{"Intro", "Next"},
+			markdown: []string{"First **bold**.", "Second"},
+		},
+		{
+			name:     "page without body tag",
+			page:     `<title>Bare</title><h1>Only</h1><p>Text</p>`,
+			headings: []string{"Only"},
+			markdown: []string{"Text"},
+		},
+		{
+			name:     "content before the first heading",
+			page:     `<html><head><title>Page</title></head><body><p>Preface</p><h1>Body</h1><p>x</p></body></html>`,
+			headings: []string{"Page", "Body"},
+			markdown: []string{"Preface", "x"},
+		},
+		{
+			name:     "text is escaped",
+			page:     `<body><h1>H</h1><p>snake_case, 2*3, [x] and a|b</p></body>`,
+			headings: []string{"H"},
+			markdown: []string{`snake\_case, 2\*3, \[x\] and a\|b`},
+		},
+		{
+			name:     "table cells keep escaped pipes",
+			page:     `<body><h1>H</h1><table><tr><th>A</th><th>B</th></tr><tr><td>a|b</td><td><code>x</code></td></tr></table></body>`,
+			headings: []string{"H"},
+			markdown: []string{"| A | B |\n| --- | --- |\n| a\\|b | `x` |"},
+		},
+		{
+			name:     "mis-nested and unclosed tags",
+			page:     `<body><h1>H</h1><p>one <b>two <i>three</b> four</i><p>five<ul><li>a<li>b</ul></body>`,
+			headings: []string{"H"},
+			markdown: []string{"one **two *three*** *four*\n\nfive\n\n- a\n- b"},
+		},
+		{
+			name:     "upper-case tags and multi-byte text",
+			page:     `<BODY><H1>İstanbul</H1><P>İ <SCRIPT>var x = "</P>";</SCRIPT>after</P></BODY>`,
+			headings: []string{"İstanbul"},
+			markdown: []string{"İ after"},
+		},
+		{
+			name:     "code blocks and callouts",
+			page:     `<body><h1>H</h1><pre><code class="language-go">fmt.Println("*")</code></pre><div class="admonition warning"><p class="admonition-title">Careful</p><p>Hot</p></div></body>`,
+			headings: []string{"H"},
+			markdown: []string{"```go\nfmt.Println(\"*\")\n```\n\n:::warning Careful\nHot\n:::"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "page.html")
+			if err := os.WriteFile(file, []byte(tt.page), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			parser, err := NewParser(filepath.Join(dir, "out"), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := parser.ParseFiles([]string{file})
+			if err != nil {
+				t.Fatalf("ParseFiles: %v", err)
+			}
+
+			if len(doc.Sections) != len(tt.headings) {
+				t.Fatalf("got %d sections, want %d", len(doc.Sections), len(tt.headings))
+			}
+			for i, section := range doc.Sections {
+				if section.Heading != tt.headings[i] {
+					t.Errorf("section %d heading = %q, want %q", i, section.Heading, tt.headings[i])
+				}
+				if section.Markdown != tt.markdown[i] {
+					t.Errorf("section %d markdown =\n%s\nwant\n%s", i, section.Markdown, tt.markdown[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseDirectorySkipsOutput(t *testing.T) {
+	dir := t.TempDir()
+	outputDir := filepath.Join(dir, "site")
+	for name, page := range map[string]string{
+		"index.html":      "<h1>Source</h1><p>x</p>",
+		"site/index.html": "<h1>Generated</h1><p>y</p>",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(page), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parser, err := NewParser(outputDir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := parser.ParseDirectory(dir)
+	if err != nil {
+		t.Fatalf("ParseDirectory: %v", err)
+	}
+	var headings []string
+	for _, section := range doc.Sections {
+		headings = append(headings, section.Heading)
+	}
+	if got := strings.Join(headings, ","); got != "Source" {
+		t.Errorf("got sections %q, want only the source page", got)
+	}
+}
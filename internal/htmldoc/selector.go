@@ -0,0 +1,217 @@
+package htmldoc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// selector is a parsed CSS selector list. Supported syntax covers what is
+// needed to pick the content region of a page:
+//
+//	main                     type selectors (and *)
+//	#content  .doc-body      ID and class selectors
+//	[role=main]  [data-doc]  attribute presence and equality
+//	div.page > article p     descendant and child combinators
+//	main, article, body      selector lists
+type selector []complexSelector
+
+// complexSelector is a chain of compound selectors joined by combinators,
+// stored right to left: parts[0] matches the element itself
+type complexSelector struct {
+	parts []compound
+	child []bool // child[i]: parts[i] and parts[i+1] are joined by ">"
+}
+
+// compound is a sequence of simple selectors that all apply to one element
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+// attrSelector matches an attribute, optionally with an exact value
+type attrSelector struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+// parseSelector parses a CSS selector list
+func parseSelector(text string) (selector, error) {
+	var sel selector
+	for _, part := range strings.Split(text, ",") {
+		complex, err := parseComplex(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", text, err)
+		}
+		sel = append(sel, complex)
+	}
+	return sel, nil
+}
+
+// parseComplex parses one selector of a selector list
+func parseComplex(text string) (complexSelector, error) {
+	if text == "" {
+		return complexSelector{}, fmt.Errorf("empty selector")
+	}
+
+	// Put spaces around ">" so combinators split like compounds
+	fields := strings.Fields(strings.ReplaceAll(text, ">", " > "))
+
+	var parts []compound
+	var child []bool
+	pendingChild := false
+	for _, field := range fields {
+		if field == ">" {
+			if len(parts) == 0 || pendingChild {
+				return complexSelector{}, fmt.Errorf("misplaced '>'")
+			}
+			pendingChild = true
+			continue
+		}
+		c, err := parseCompound(field)
+		if err != nil {
+			return complexSelector{}, err
+		}
+		if len(parts) > 0 {
+			child = append(child, pendingChild)
+		}
+		parts = append(parts, c)
+		pendingChild = false
+	}
+	if pendingChild {
+		return complexSelector{}, fmt.Errorf("selector ends with '>'")
+	}
+
+	// Reverse so matching can start at the element itself
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	for i, j := 0, len(child)-1; i < j; i, j = i+1, j-1 {
+		child[i], child[j] = child[j], child[i]
+	}
+	return complexSelector{parts: parts, child: child}, nil
+}
+
+// parseCompound parses a compound selector such as div#main.content[role=main]
+func parseCompound(text string) (compound, error) {
+	var c compound
+	i := 0
+
+	// Optional type selector
+	for i < len(text) && (isNameByte(text[i]) || text[i] == '*') {
+		i++
+	}
+	if tag := strings.ToLower(text[:i]); tag != "*" {
+		c.tag = tag
+	}
+
+	for i < len(text) {
+		switch text[i] {
+		case '#', '.':
+			kind := text[i]
+			i++
+			start := i
+			for i < len(text) && isNameByte(text[i]) {
+				i++
+			}
+			if i == start {
+				return c, fmt.Errorf("missing name after '%c'", kind)
+			}
+			if kind == '#' {
+				c.id = text[start:i]
+			} else {
+				c.classes = append(c.classes, text[start:i])
+			}
+
+		case '[':
+			end := strings.IndexByte(text[i:], ']')
+			if end < 0 {
+				return c, fmt.Errorf("unclosed '['")
+			}
+			body := text[i+1 : i+end]
+			i += end + 1
+
+			name, value, hasValue := strings.Cut(body, "=")
+			a := attrSelector{name: strings.ToLower(strings.TrimSpace(name)), hasValue: hasValue}
+			if hasValue {
+				a.value = strings.Trim(strings.TrimSpace(value), `"'`)
+			}
+			if a.name == "" {
+				return c, fmt.Errorf("missing attribute name")
+			}
+			c.attrs = append(c.attrs, a)
+
+		default:
+			return c, fmt.Errorf("unsupported syntax at %q", text[i:])
+		}
+	}
+
+	return c, nil
+}
+
+// matches reports whether the element matches the compound selector
+func (c compound) matches(n *node) bool {
+	if n.tag == "" || (c.tag != "" && n.tag != c.tag) {
+		return false
+	}
+	if c.id != "" && n.attr("id") != c.id {
+		return false
+	}
+	for _, class := range c.classes {
+		if !n.hasClass(class) {
+			return false
+		}
+	}
+	for _, a := range c.attrs {
+		value, ok := n.attrs[a.name]
+		if !ok || (a.hasValue && value != a.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether the element matches the complex selector
+func (s complexSelector) matches(n *node) bool {
+	return s.matchFrom(n, 0)
+}
+
+// matchFrom matches parts[i:] with parts[i] applied to n
+func (s complexSelector) matchFrom(n *node, i int) bool {
+	if !s.parts[i].matches(n) {
+		return false
+	}
+	if i == len(s.parts)-1 {
+		return true
+	}
+	if s.child[i] {
+		return n.parent != nil && s.matchFrom(n.parent, i+1)
+	}
+	for ancestor := n.parent; ancestor != nil; ancestor = ancestor.parent {
+		if s.matchFrom(ancestor, i+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// first returns the first element matching the selector list. Selectors are
+// tried in the order they are listed, so "main, article, body" works as a
+// list of fallbacks.
+func (sel selector) first(root *node) *node {
+	for _, s := range sel {
+		if found := root.find(s.matches); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// isNameByte reports whether c can appear in a type, class or ID name;
+// bytes of non-ASCII characters are accepted as CSS identifiers allow them
+func isNameByte(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+		c == '-' || c == '_' || c >= 0x80
+}
@@ -0,0 +1,72 @@
+package htmldoc
+
+import "testing"
+
+func TestSelectorFirst(t *testing.T) {
+	const page = `<!DOCTYPE html>
+<html><head><title>T</title></head>
+<body>
+<nav id="nav"><ul><li>Menu</li></ul></nav>
+<div class="page wide">
+  <article id="doc" data-kind="guide">
+    <section role="main"><p class="lead">Lead</p></section>
+  </article>
+</div>
+<p class="lead">Outside</p>
+</body></html>`
+	root := parseHTML(page)
+
+	tests := []struct {
+		selector string
+		wantTag  string
+		wantID   string // id or text that identifies the match
+	}{
+		{"article", "article", "doc"},
+		{"#doc", "article", "doc"},
+		{".page.wide", "div", ""},
+		{"[role=main]", "section", ""},
+		{`[data-kind="guide"]`, "article", "doc"},
+		{"[data-kind]", "article", "doc"},
+		{"div.page > article", "article", "doc"},
+		{"body > article", "", ""},
+		{"div p.lead", "p", "Lead"},
+		{"body > p.lead", "p", "Outside"},
+		{"main, article, body", "article", "doc"},
+		{"main, aside", "", ""},
+		{"*#doc", "article", "doc"},
+		{"BODY", "body", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := parseSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("parseSelector(%q): %v", tt.selector, err)
+			}
+			got := sel.first(root)
+			if tt.wantTag == "" {
+				if got != nil {
+					t.Fatalf("got <%s>, want no match", got.tag)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("got no match, want <%s>", tt.wantTag)
+			}
+			if got.tag != tt.wantTag {
+				t.Errorf("got <%s>, want <%s>", got.tag, tt.wantTag)
+			}
+			if tt.wantID != "" && got.attr("id") != tt.wantID && collapseSpace(got.textContent()) != tt.wantID {
+				t.Errorf("matched the wrong <%s>: id %q, text %q", got.tag, got.attr("id"), got.textContent())
+			}
+		})
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	for _, text := range []string{"", "main,", "> p", "div >", "div > > p", "a:hover", "[=x]", "[x", "#", "p."} {
+		if _, err := parseSelector(text); err == nil {
+			t.Errorf("parseSelector(%q) succeeded, want an error", text)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package man
+
+import (
+	"strings"
+
+	"docTrainerGO/internal/md"
+)
+
+// specialChars maps roff special character names (\(xx and \[name]) to text
+var specialChars = map[string]string{
+	"em": "—", "en": "–", "hy": "-", "mi": "-", "bu": "•", "aq": "'",
+	"dq": "\"", "lq": "“", "rq": "”", "oq": "‘", "cq": "’", "co": "©",
+	"rg": "®", "tm": "™", "de": "°", "ti": "~", "ha": "^", "rs": "\\",
+	"ga": "`", "ua": "↑", "da": "↓", "<-": "←", "->": "→", "<=": "≤",
+	">=": "≥", "!=": "≠", "mu": "×", "pl": "+", "eq": "=", "sl": "/",
+	"ba": "|", "or": "|", "lh": "☜", "rh": "☞", "sc": "§", "ps": "¶",
+}
+
+// inline converts a line of roff text to Markdown, turning font escapes
+// into emphasis
+func inline(s string) string {
+	return convert(s, true)
+}
+
+// plain converts a line of roff text to plain text, dropping font changes
+func plain(s string) string {
+	return convert(s, false)
+}
+
+// segment is a run of text set in one font
+type segment struct {
+	font string // Markdown marker of the font, empty for roman
+	text string
+}
+
+// convert decodes the escapes of a line of roff text
+func convert(s string, markdown bool) string {
+	var segments []segment
+	font := ""     // Markdown marker of the current font
+	previous := "" // marker of the previous font, for \fP
+
+	setFont := func(marker string) {
+		if !markdown || marker == font {
+			return
+		}
+		previous, font = font, marker
+	}
+
+	// write adds text in the current font, extending the last segment when
+	// the font has not changed since
+	write := func(text string) {
+		if n := len(segments); n > 0 && segments[n-1].font == font {
+			segments[n-1].text += text
+			return
+		}
+		segments = append(segments, segment{font: font, text: text})
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 >= len(s) {
+			write(s[i : i+1])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'f':
+			// Font change: \fB, \fI, \fR, \fP, \f(BI or \f[B]
+			name, n := escapeName(s[i+1:])
+			i += n
+			switch name {
+			case "B", "CB", "BI", "3", "4":
+				setFont("**")
+			case "I", "CI", "2":
+				setFont("*")
+			case "P":
+				setFont(previous)
+			default:
+				setFont("")
+			}
+		case '(', '[':
+			name, n := escapeName(s[i:])
+			i += n - 1
+			if text, ok := specialChars[name]; ok {
+				write(text)
+			}
+		case '*':
+			// Predefined strings such as \*(lq and \*R
+			name, n := escapeName(s[i+1:])
+			i += n
+			switch name {
+			case "lq", "Lq":
+				write("“")
+			case "rq", "Rq":
+				write("”")
+			case "R":
+				write("®")
+			case "Tm":
+				write("™")
+			}
+		case 's':
+			// Size change: \s+1, \s-1, \s0
+			j := i + 1
+			if j < len(s) && (s[j] == '+' || s[j] == '-') {
+				j++
+			}
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			i = j - 1
+		case 'e', '\\':
+			write("\\")
+		case '-':
+			write("-")
+		case '~', ' ', '0':
+			write(" ")
+		case '&', '|', '^', ':', ',', '/', 'c', '%':
+			// Zero-width characters and hyphenation hints
+		case '\'':
+			write("'")
+		case '`':
+			write("`")
+		case '.':
+			write(".")
+		case 'n', 'k', 'g':
+			// Number registers and other escapes with a name argument
+			_, n := escapeName(s[i+1:])
+			i += n
+		default:
+			write(s[i : i+1])
+		}
+	}
+
+	if !markdown {
+		if len(segments) == 0 {
+			return ""
+		}
+		return segments[0].text
+	}
+	return renderSegments(segments)
+}
+
+// renderSegments writes font runs as Markdown. Text is escaped so roff
+// text such as snake_case or a*b stays literal, and spaces at the edges of
+// a run are moved outside its markers, which would not close otherwise.
+func renderSegments(segments []segment) string {
+	var b strings.Builder
+	for _, seg := range segments {
+		text := md.EscapeText(seg.text)
+		core := strings.TrimSpace(text)
+		if seg.font == "" || core == "" {
+			b.WriteString(text)
+			continue
+		}
+		lead := text[:strings.Index(text, core)]
+		b.WriteString(lead)
+		b.WriteString(seg.font)
+		b.WriteString(core)
+		b.WriteString(seg.font)
+		b.WriteString(text[len(lead)+len(core):])
+	}
+	return b.String()
+}
+
+// styled converts roff text set in the given font (B, I or R), so that
+// font escapes inside it join the surrounding runs
+func styled(font byte, text string) string {
+	return inline("\\f" + string(font) + text)
+}
+
+// escapeName reads the name argument of an escape: a single character, a
+// two character name after "(", or a bracketed name. It returns the name and
+// how many bytes were consumed.
+func escapeName(s string) (string, int) {
+	if s == "" {
+		return "", 0
+	}
+	switch s[0] {
+	case '(':
+		if len(s) < 3 {
+			return "", len(s)
+		}
+		return s[1:3], 3
+	case '[':
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return s[1:], len(s)
+		}
+		return s[1:end], end + 1
+	}
+	return s[:1], 1
+}
+
+// mdocCallable formats the mdoc macros that may appear inside a line
+var mdocCallable = map[string]func(arg string) string{
+	"Fl": func(arg string) string { return "**-" + arg + "**" },
+	"Cm": func(arg string) string { return "**" + arg + "**" },
+	"Ic": func(arg string) string { return "**" + arg + "**" },
+	"Sy": func(arg string) string { return "**" + arg + "**" },
+	"Ar": func(arg string) string { return "*" + arg + "*" },
+	"Em": func(arg string) string { return "*" + arg + "*" },
+	"Pa": func(arg string) string { return "*" + arg + "*" },
+	"Va": func(arg string) string { return "*" + arg + "*" },
+	"Ev": func(arg string) string { return "`" + arg + "`" },
+	"Li": func(arg string) string { return "`" + arg + "`" },
+	"Ql": func(arg string) string { return "`" + arg + "`" },
+	"Dv": func(arg string) string { return "`" + arg + "`" },
+	"Er": func(arg string) string { return "`" + arg + "`" },
+	"Dq": func(arg string) string { return "“" + arg + "”" },
+	"Sq": func(arg string) string { return "‘" + arg + "’" },
+	"Pq": func(arg string) string { return "(" + arg + ")" },
+	"Op": func(arg string) string { return "[" + arg + "]" },
+	"Oo": func(arg string) string { return "[" + arg },
+	"Oc": func(arg string) string { return "]" + arg },
+}
+
+// mdocInline formats the arguments of an mdoc line. Callable macros among
+// the arguments apply to what follows; punctuation attaches to the word
+// before it.
+func mdocInline(args []string, pg *pageParser) string {
+	var words []string
+	attach := false // the next word joins the previous one (after Ns)
+
+	add := func(word string) {
+		if word == "" {
+			return
+		}
+		if (attach || isPunctuation(word)) && len(words) > 0 {
+			words[len(words)-1] += word
+		} else {
+			words = append(words, word)
+		}
+		attach = false
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "Ns":
+			attach = true
+		case arg == "Nm":
+			// A name argument, or the page name when omitted
+			name := pg.mdocNames
+			if i+1 < len(args) && !isMacro(args[i+1]) && !isPunctuation(args[i+1]) {
+				i++
+				name = args[i]
+			}
+			add("**" + plain(name) + "**")
+		case arg == "Xr":
+			// Cross reference: name(section)
+			if i+2 < len(args) {
+				add(plain(args[i+1]) + "(" + args[i+2] + ")")
+				i += 2
+			}
+		case mdocCallable[arg] != nil:
+			// Enclosing macros take the rest of the line, others one word
+			format := mdocCallable[arg]
+			switch arg {
+			case "Op", "Dq", "Sq", "Pq":
+				add(format(mdocInline(args[i+1:], pg)))
+				i = len(args)
+			case "Oo", "Oc":
+				add(format(""))
+			default:
+				var operands []string
+				for i+1 < len(args) && !isMacro(args[i+1]) && !isPunctuation(args[i+1]) {
+					i++
+					operands = append(operands, plain(args[i]))
+				}
+				if len(operands) == 0 && arg == "Fl" {
+					add(format(""))
+				}
+				for _, operand := range operands {
+					add(format(operand))
+				}
+			}
+		case isMacro(arg):
+			// Other callable macros are dropped, their arguments kept
+		default:
+			add(inline(arg))
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// isMacro reports whether an mdoc argument names a macro
+func isMacro(arg string) bool {
+	if len(arg) != 2 || arg[0] < 'A' || arg[0] > 'Z' || arg[1] < 'a' || arg[1] > 'z' {
+		return false
+	}
+	switch arg {
+	case "Ns", "Nm", "Xr", "Ad", "An", "Ap", "Cd", "Fa", "Fn", "Ft", "Lk", "Mt", "No", "Sx", "Tn", "Ux":
+		return true
+	}
+	return mdocCallable[arg] != nil
+}
+
+// isPunctuation reports whether an mdoc argument is closing punctuation
+func isPunctuation(arg string) bool {
+	switch arg {
+	case ".", ",", ":", ";", ")", "]", "?", "!":
+		return true
+	}
+	return false
+}
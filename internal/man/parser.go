@@ -0,0 +1,486 @@
+package man
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/md"
+)
+
+// manFileRegex matches man page file names such as ls.1, git-log.1.gz or printf.3p
+var manFileRegex = regexp.MustCompile(`\.[1-9][a-z]*(\.gz)?$`)
+
+// Parser reads roff man pages written with the man(7) macros; the common
+// mdoc(7) macros used by BSD pages are understood as well
+type Parser struct {
+	sectionID int
+}
+
+// NewParser creates a new man page parser
+func NewParser() *Parser {
+	return &Parser{sectionID: 0}
+}
+
+// ParsePath parses a man page or every man page in a directory
+func (p *Parser) ParsePath(path string) (*document.Document, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return p.ParseFiles([]string{path})
+	}
+
+	var files []string
+	err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && manFileRegex.MatchString(info.Name()) {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no man pages found in %s", path)
+	}
+
+	fmt.Printf("Found %d man pages\n", len(files))
+	return p.ParseFiles(files)
+}
+
+// ParseFiles parses man pages into one document; every page becomes a
+// section with its .SH and .SS headings as subsections
+func (p *Parser) ParseFiles(files []string) (*document.Document, error) {
+	doc := &document.Document{
+		Title:    "Manual Pages",
+		Sections: make([]document.Section, 0),
+	}
+
+	for _, file := range files {
+		lines, err := readPage(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		page := &pageParser{parser: p, path: file}
+		page.parse(lines)
+		doc.Sections = append(doc.Sections, page.sections...)
+	}
+
+	if len(files) == 1 && len(doc.Sections) > 0 {
+		doc.Title = doc.Sections[0].Heading
+	}
+
+	for i := range doc.Sections {
+		md.FinishSection(&doc.Sections[i])
+	}
+	return doc, nil
+}
+
+// readPage reads a man page, decompressing .gz files, and joins lines
+// ending in an escaped newline
+func readPage(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(zr); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+	}
+
+	var lines []string
+	var pending string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := pending + scanner.Text()
+		pending = ""
+		if strings.HasSuffix(line, `\`) && !strings.HasSuffix(line, `\\`) {
+			pending = strings.TrimSuffix(line, `\`)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if pending != "" {
+		lines = append(lines, pending)
+	}
+	return lines, scanner.Err()
+}
+
+// pageParser holds the state for converting one man page to sections
+type pageParser struct {
+	parser   *Parser
+	path     string
+	sections []document.Section
+	current  *document.Section
+	lines    []string // Markdown of the current section
+
+	paragraph []string // text of the running paragraph or list item
+	item      string   // list item marker and tag, empty outside lists
+	tagNext   bool     // the next text line is the tag of a .TP item
+	code      []string // lines of a no-fill block
+	inCode    bool
+	mdocNames string // page name from .Nm, repeated by empty .Nm macros
+	url       string // target of an open .UR or .MT link
+	urlStart  int    // paragraph index where the link text starts
+}
+
+// parse converts the lines of a page
+func (pg *pageParser) parse(lines []string) {
+	for _, line := range lines {
+		// Comments
+		if strings.HasPrefix(line, `.\"`) || strings.HasPrefix(line, `'\"`) || strings.HasPrefix(line, `.\#`) {
+			continue
+		}
+		if idx := strings.Index(line, `\"`); idx >= 0 && !strings.HasPrefix(line[max(idx-1, 0):], `\\"`) {
+			line = line[:idx]
+		}
+
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			name, args := splitMacro(line[1:])
+			pg.macro(name, args)
+			continue
+		}
+		pg.text(line)
+	}
+	pg.endSection()
+}
+
+// text handles a text line
+func (pg *pageParser) text(line string) {
+	if pg.inCode {
+		pg.code = append(pg.code, plain(line))
+		return
+	}
+	if strings.TrimSpace(line) == "" {
+		pg.flushParagraph()
+		return
+	}
+	pg.addText(inline(line))
+}
+
+// addText adds formatted text to the running paragraph, or makes it the tag
+// of a .TP item
+func (pg *pageParser) addText(text string) {
+	if pg.tagNext {
+		pg.tagNext = false
+		pg.item = "- " + text + ":"
+		return
+	}
+	pg.paragraph = append(pg.paragraph, text)
+}
+
+// macro handles a request or macro line
+func (pg *pageParser) macro(name string, args []string) {
+	switch name {
+	// Page and section headings
+	case "TH", "Dt":
+		if len(args) > 0 {
+			title := strings.ToLower(args[0])
+			if len(args) > 1 {
+				title += "(" + args[1] + ")"
+			}
+			pg.startPage(plain(title))
+		}
+	case "SH", "Sh":
+		pg.heading(2, args)
+	case "SS", "Ss":
+		pg.heading(3, args)
+
+	// Paragraphs and lists
+	case "PP", "P", "LP", "Pp", "Lp", "sp", "br", "RE", "El":
+		pg.flushParagraph()
+	case "TP", "TQ":
+		pg.flushParagraph()
+		pg.tagNext = true
+	case "IP":
+		pg.flushParagraph()
+		pg.item = itemMarker(args)
+	case "It":
+		pg.flushParagraph()
+		if len(args) == 0 {
+			pg.item = "-"
+		} else {
+			pg.item = "- " + mdocInline(args, pg) + ":"
+		}
+	case "RS", "Bl", "PD", "in", "ti", "ne", "na", "ad", "hy", "nh", "ft", "ps", "Os", "Dd", "YS":
+		// Indentation, spacing and layout requests have no Markdown equivalent
+
+	// No-fill (preformatted) blocks
+	case "nf", "EX", "Bd":
+		if name == "Bd" && !containsArg(args, "-literal", "-unfilled") {
+			pg.flushParagraph()
+			return
+		}
+		pg.flushParagraph()
+		pg.inCode = true
+		pg.code = nil
+	case "fi", "EE", "Ed":
+		pg.endCode()
+
+	// Fonts
+	case "B", "SB":
+		pg.addText(styled('B', strings.Join(args, " ")))
+	case "I":
+		pg.addText(styled('I', strings.Join(args, " ")))
+	case "SM":
+		pg.addText(inline(strings.Join(args, " ")))
+	case "BR", "RB", "BI", "IB", "IR", "RI":
+		pg.addText(alternate(name, args))
+
+	// Links and synopsis
+	case "UR", "MT":
+		if len(args) > 0 {
+			pg.url = args[0]
+			if name == "MT" {
+				pg.url = "mailto:" + pg.url
+			}
+			pg.urlStart = len(pg.paragraph)
+		}
+	case "UE", "ME":
+		pg.endLink(strings.Join(args, ""))
+	case "SY":
+		pg.flushParagraph()
+		pg.addText(styled('B', strings.Join(args, " ")))
+	case "OP":
+		pg.addText("[" + alternate("BI", args) + "]")
+
+	// mdoc name and description lines
+	case "Nm":
+		if len(args) > 0 && pg.mdocNames == "" {
+			pg.mdocNames = args[0]
+		}
+		pg.addText(mdocInline(append([]string{"Nm"}, args...), pg))
+	case "Nd":
+		pg.addText("— " + mdocInline(args, pg))
+
+	default:
+		// Other mdoc macros format their arguments inline; unknown roff
+		// requests are dropped
+		if len(name) == 2 && name[0] >= 'A' && name[0] <= 'Z' && name[1] >= 'a' && name[1] <= 'z' {
+			pg.addText(mdocInline(append([]string{name}, args...), pg))
+		}
+	}
+}
+
+// endLink turns the text since .UR into a link to its target; without text
+// the target itself is shown
+func (pg *pageParser) endLink(trailing string) {
+	if pg.url == "" {
+		return
+	}
+	start := min(pg.urlStart, len(pg.paragraph))
+	text := strings.Join(pg.paragraph[start:], " ")
+	if text == "" {
+		text = strings.TrimPrefix(pg.url, "mailto:")
+	}
+	pg.paragraph = append(pg.paragraph[:start], fmt.Sprintf("[%s](%s)%s", text, pg.url, trailing))
+	pg.url = ""
+}
+
+// startPage starts the section of a new page
+func (pg *pageParser) startPage(title string) {
+	pg.endSection()
+	pg.newSection(1, title)
+}
+
+// heading starts a .SH or .SS section. The NAME section stays in the page
+// section, which it describes.
+func (pg *pageParser) heading(level int, args []string) {
+	heading := plain(strings.Join(args, " "))
+	if heading == "" {
+		return
+	}
+	if level == 2 && strings.EqualFold(heading, "NAME") && pg.current != nil && pg.current.Level == 1 && len(pg.lines) == 0 {
+		pg.flushParagraph()
+		return
+	}
+	pg.endSection()
+	pg.newSection(level, titleCase(heading))
+}
+
+// newSection starts a section; pages without .TH get one named after the file
+func (pg *pageParser) newSection(level int, heading string) {
+	if level > 1 && len(pg.sections) == 0 && pg.current == nil {
+		base := filepath.Base(pg.path)
+		base = strings.TrimSuffix(base, ".gz")
+		pg.newSection(1, base)
+		pg.endSection()
+	}
+
+	pg.parser.sectionID++
+	pg.current = &document.Section{
+		ID:      fmt.Sprintf("section-%d", pg.parser.sectionID),
+		Level:   level,
+		Heading: heading,
+		Source:  pg.path,
+	}
+}
+
+// endSection stores the current section
+func (pg *pageParser) endSection() {
+	pg.endCode()
+	pg.flushParagraph()
+	if pg.current == nil {
+		if len(pg.lines) > 0 {
+			pg.newSection(1, filepath.Base(pg.path))
+		} else {
+			return
+		}
+	}
+	pg.current.Markdown = strings.TrimSpace(strings.Join(pg.lines, "\n"))
+	pg.sections = append(pg.sections, *pg.current)
+	pg.current = nil
+	pg.lines = nil
+}
+
+// flushParagraph writes the running paragraph or list item
+func (pg *pageParser) flushParagraph() {
+	pg.tagNext = false
+	text := strings.TrimSpace(strings.Join(pg.paragraph, " "))
+	pg.paragraph = nil
+
+	if pg.item != "" {
+		line := strings.TrimSpace(pg.item + " " + text)
+		pg.item = ""
+		// Keep consecutive items in one list
+		if n := len(pg.lines); n == 0 || !strings.HasPrefix(pg.lines[n-1], "- ") && !strings.HasPrefix(pg.lines[n-1], "1. ") {
+			pg.lines = append(pg.lines, "")
+		}
+		pg.lines = append(pg.lines, line)
+		return
+	}
+	if text != "" {
+		pg.lines = append(pg.lines, "", text)
+	}
+}
+
+// endCode writes a finished no-fill block as a code block
+func (pg *pageParser) endCode() {
+	if !pg.inCode {
+		return
+	}
+	pg.inCode = false
+	if len(pg.code) > 0 {
+		pg.lines = append(pg.lines, "", "```", strings.Join(pg.code, "\n"), "```")
+	}
+	pg.code = nil
+}
+
+// itemMarker returns the list marker for an .IP tag
+func itemMarker(args []string) string {
+	if len(args) == 0 {
+		return "-"
+	}
+	tag := plain(args[0])
+	switch {
+	case tag == "" || tag == "•" || tag == "*" || tag == "-" || tag == "o":
+		return "-"
+	case strings.TrimRight(tag, ".)") != tag && strings.Trim(tag, "0123456789.)") == "":
+		return "1."
+	}
+	return "- " + inline(args[0]) + ":"
+}
+
+// splitMacro splits a macro line into its name and arguments. Double-quoted
+// arguments may contain spaces; "" inside quotes is a literal quote.
+func splitMacro(line string) (string, []string) {
+	line = strings.TrimLeft(line, " \t")
+	var fields []string
+	for i := 0; i < len(line); {
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		if i >= len(line) {
+			break
+		}
+		if line[i] == '"' {
+			var b strings.Builder
+			i++
+			for i < len(line) {
+				if line[i] == '"' {
+					if i+1 < len(line) && line[i+1] == '"' {
+						b.WriteByte('"')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteByte(line[i])
+				i++
+			}
+			fields = append(fields, b.String())
+			continue
+		}
+		start := i
+		for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+			i++
+		}
+		fields = append(fields, line[start:i])
+	}
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return fields[0], fields[1:]
+}
+
+// alternate formats the arguments of .BR-style macros, which alternate
+// between two fonts without spaces in between. The arguments are converted
+// as one line, so runs that end up in the same font are merged.
+func alternate(name string, args []string) string {
+	var b strings.Builder
+	for i, arg := range args {
+		b.WriteString(`\f`)
+		b.WriteByte(name[i%2])
+		b.WriteString(arg)
+	}
+	return inline(b.String())
+}
+
+// titleCase turns an all-caps heading such as "SEE ALSO" into "See Also"
+func titleCase(heading string) string {
+	if strings.ToUpper(heading) != heading {
+		return heading
+	}
+	words := strings.Fields(strings.ToLower(heading))
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToTitle(r)) + w[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// containsArg reports whether args contains any of the values
+func containsArg(args []string, values ...string) bool {
+	for _, arg := range args {
+		for _, v := range values {
+			if arg == v {
+				return true
+			}
+		}
+	}
+	return false
+}
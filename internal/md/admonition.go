@@ -0,0 +1,125 @@
+package md
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"docTrainerGO/internal/document"
+)
+
+// Admonitions are recognised in three forms:
+//
+//	> [!WARNING]            GitHub alert syntax
+//	> Text...
+//
+//	:::warning Optional title
+//	Text...
+//	:::
+//
+//	> **Warning:** Text...  legacy bold-label blockquotes
+//
+// In section content they are kept as ":::type[title] body :::" markers so
+// the reader and chat context can tell them apart from regular text.
+var (
+	githubAlertRegex = regexp.MustCompile(`^\s*>\s*\[!(\w+)\]\s*(.*)$`)
+	fenceStartRegex  = regexp.MustCompile(`^\s*:::\s*(\w+)\s*(.*)$`)
+	fenceEndRegex    = regexp.MustCompile(`^\s*:::\s*$`)
+	boldLabelRegex   = regexp.MustCompile(`^\s*>\s*\*\*(Note|Tip|Important|Warning|Caution|Danger|Info):?\*\*:?\s*(.*)$`)
+	quoteLineRegex   = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	headingLineRegex = regexp.MustCompile(`^#{1,6}\s+`)
+)
+
+// admonition is an admonition block being collected by the parser
+type admonition struct {
+	kind     string
+	title    string
+	fenced   bool
+	inCode   bool // inside a code fence of a fenced admonition
+	unclosed bool // ended by a heading instead of a closing :::
+	lines    []string
+}
+
+// startAdmonition returns a new admonition if line opens one
+func startAdmonition(line string) *admonition {
+	if m := githubAlertRegex.FindStringSubmatch(line); m != nil {
+		a := &admonition{kind: strings.ToLower(m[1])}
+		if text := strings.TrimSpace(m[2]); text != "" {
+			a.lines = append(a.lines, text)
+		}
+		return a
+	}
+	if m := fenceStartRegex.FindStringSubmatch(line); m != nil {
+		return &admonition{kind: strings.ToLower(m[1]), title: strings.TrimSpace(m[2]), fenced: true}
+	}
+	if m := boldLabelRegex.FindStringSubmatch(line); m != nil {
+		a := &admonition{kind: strings.ToLower(m[1])}
+		if text := strings.TrimSpace(m[2]); text != "" {
+			a.lines = append(a.lines, text)
+		}
+		return a
+	}
+	return nil
+}
+
+// add feeds the next line to the admonition. It returns consumed=false when
+// the line is not part of the block and must be processed normally, and
+// closed=true once the block has ended. A fenced block without its closing
+// ::: ends at the next heading outside a code fence.
+func (a *admonition) add(line string) (consumed, closed bool) {
+	if a.fenced {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			a.inCode = !a.inCode
+		}
+		if !a.inCode && fenceEndRegex.MatchString(line) {
+			return true, true
+		}
+		if !a.inCode && headingLineRegex.MatchString(line) {
+			a.unclosed = true
+			return false, true
+		}
+		a.lines = append(a.lines, line)
+		return true, false
+	}
+
+	m := quoteLineRegex.FindStringSubmatch(line)
+	if m == nil {
+		return false, true
+	}
+	a.lines = append(a.lines, m[1])
+	return true, false
+}
+
+// body returns the admonition text joined into a single line
+func (a *admonition) body() string {
+	parts := make([]string, 0, len(a.lines))
+	for _, line := range a.lines {
+		if line = strings.TrimSpace(line); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// marker returns the content marker for the admonition
+func (a *admonition) marker() string {
+	if a.title != "" {
+		return fmt.Sprintf(":::%s[%s] %s :::", a.kind, a.title, a.body())
+	}
+	return fmt.Sprintf(":::%s %s :::", a.kind, a.body())
+}
+
+// block returns the admonition as a fenced Markdown block, keeping its
+// lines so code blocks inside it survive
+func (a *admonition) block() string {
+	return fmt.Sprintf(":::%s %s\n%s\n:::\n\n", a.kind, a.title, strings.TrimSpace(strings.Join(a.lines, "\n")))
+}
+
+// toSection converts the admonition to its section model form
+func (a *admonition) toSection() document.Admonition {
+	return document.Admonition{
+		Type:    a.kind,
+		Title:   a.title,
+		Content: a.body(),
+	}
+}
@@ -0,0 +1,131 @@
+package md
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Conditional blocks keep content only for some audience profiles:
+//
+//	<!-- if: admin -->            shown for the admin profile
+//	<!-- if: admin, operator -->  shown for either profile
+//	<!-- if: !enduser -->         shown for every profile except enduser
+//	<!-- endif -->
+//
+// A whole file can be limited with front matter:
+//
+//	---
+//	profiles: [admin]
+//	---
+//
+// With no active profile every block and file is kept.
+var (
+	ifRegex    = regexp.MustCompile(`^\s*<!--\s*if:\s*(.+?)\s*-->\s*$`)
+	endifRegex = regexp.MustCompile(`^\s*<!--\s*endif\s*-->\s*$`)
+)
+
+// frontMatter holds the front matter fields the parser understands
+type frontMatter struct {
+	Profiles []string `yaml:"profiles"`
+}
+
+// SetProfile sets the active audience profile
+func (p *Parser) SetProfile(profile string) {
+	p.profile = strings.TrimSpace(profile)
+}
+
+// inProfile reports whether content tagged with the given profile list
+// belongs to the active profile. Names prefixed with "!" exclude a profile.
+func (p *Parser) inProfile(profiles []string) bool {
+	if p.profile == "" || len(profiles) == 0 {
+		return true
+	}
+
+	included := false
+	hasPositive := false
+	for _, name := range profiles {
+		name = strings.TrimSpace(name)
+		if negated := strings.TrimPrefix(name, "!"); negated != name {
+			if negated == p.profile {
+				return false
+			}
+			continue
+		}
+		hasPositive = true
+		if name == p.profile {
+			included = true
+		}
+	}
+	return included || !hasPositive
+}
+
+// fileInProfile checks a file's front matter against the active profile
+func (p *Parser) fileInProfile(lines []string, filePath string) (bool, error) {
+	if len(lines) == 0 || lines[0] != "---" {
+		return true, nil
+	}
+
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == "---" {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return true, nil
+	}
+
+	var fm frontMatter
+	if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "\n")), &fm); err != nil {
+		return false, fmt.Errorf("%s: invalid front matter: %w", filePath, err)
+	}
+	return p.inProfile(fm.Profiles), nil
+}
+
+// applyConditionals drops the lines of conditional blocks outside the active
+// profile. It returns the kept lines and their original line numbers.
+func (p *Parser) applyConditionals(lines []string, filePath string) ([]string, []int, error) {
+	kept := make([]string, 0, len(lines))
+	lineNums := make([]int, 0, len(lines))
+
+	// Stack of open blocks; a line is kept only if every open block is active
+	var openLines []int
+	var active []bool
+	var fences codeFences
+
+	for i, line := range lines {
+		if !fences.scan(line) {
+			if m := ifRegex.FindStringSubmatch(line); m != nil {
+				openLines = append(openLines, i+1)
+				active = append(active, p.inProfile(strings.Split(m[1], ",")))
+				continue
+			}
+			if endifRegex.MatchString(line) {
+				if len(active) == 0 {
+					return nil, nil, fmt.Errorf("%s:%d: endif without matching if", filePath, i+1)
+				}
+				openLines = openLines[:len(openLines)-1]
+				active = active[:len(active)-1]
+				continue
+			}
+		}
+
+		keep := true
+		for _, a := range active {
+			keep = keep && a
+		}
+		if keep {
+			kept = append(kept, line)
+			lineNums = append(lineNums, i+1)
+		}
+	}
+
+	if len(openLines) > 0 {
+		return nil, nil, fmt.Errorf("%s:%d: if block is not closed", filePath, openLines[len(openLines)-1])
+	}
+	return kept, lineNums, nil
+}
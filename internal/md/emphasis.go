@@ -0,0 +1,188 @@
+package md
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// delimiter is a run of "*" or "_" that may open or close emphasis
+type delimiter struct {
+	char     byte
+	length   int // length of the whole run
+	count    int // characters not yet used by a match
+	canOpen  bool
+	canClose bool
+	opens    []string // tags opened after the run, innermost first
+	closes   []string // tags closed before the run, innermost first
+}
+
+// emphasisPiece is either literal text or a delimiter run
+type emphasisPiece struct {
+	text  string
+	delim *delimiter
+}
+
+// renderEmphasis converts **strong** and *em* in already escaped text. Runs
+// of "*" and "_" are matched with a delimiter stack as in CommonMark, so the
+// output is always well nested and unmatched delimiters stay literal.
+func renderEmphasis(text string) string {
+	if !strings.ContainsAny(text, "*_") {
+		return text
+	}
+
+	// Split the text into literal pieces and delimiter runs
+	var pieces []emphasisPiece
+	var stack []*delimiter
+	last := 0
+	for i := 0; i < len(text); {
+		c := text[i]
+		if c != '*' && c != '_' {
+			i++
+			continue
+		}
+		end := i
+		for end < len(text) && text[end] == c {
+			end++
+		}
+		if last < i {
+			pieces = append(pieces, emphasisPiece{text: text[last:i]})
+		}
+		d := newDelimiter(text, i, end)
+		pieces = append(pieces, emphasisPiece{delim: d})
+		stack = append(stack, d)
+		last, i = end, end
+	}
+	if last < len(text) {
+		pieces = append(pieces, emphasisPiece{text: text[last:]})
+	}
+
+	// Match every closer with the nearest compatible opener before it. The
+	// stack is a linked list through prev, so delimiters between a matched
+	// pair are dropped at once, and bottom records, per kind of closer, below
+	// which a search already failed (openers_bottom in CommonMark). Each
+	// delimiter is therefore passed over a bounded number of times.
+	prev := make([]int, len(stack))
+	for i := range prev {
+		prev[i] = i - 1
+	}
+	var bottom [2][2][3]int // by char, closer.canOpen and closer.length%3
+	for i := range bottom {
+		for j := range bottom[i] {
+			bottom[i][j] = [3]int{-1, -1, -1}
+		}
+	}
+	remove := func(c int) {
+		if c+1 < len(prev) {
+			prev[c+1] = prev[c]
+		}
+	}
+
+	for c, closer := range stack {
+		if !closer.canClose {
+			continue
+		}
+		kind := &bottom[boolIndex(closer.char == '_')][boolIndex(closer.canOpen)][closer.length%3]
+		for closer.count > 0 {
+			o := prev[c]
+			for ; o > *kind; o = prev[o] {
+				if opener := stack[o]; opener.canOpen && opener.char == closer.char && !ruleOfThree(opener, closer) {
+					break
+				}
+			}
+			if o <= *kind {
+				// Later closers of this kind cannot match below here either
+				*kind = prev[c]
+				if !closer.canOpen {
+					remove(c)
+				}
+				break
+			}
+
+			opener := stack[o]
+			n, tag := 1, "em"
+			if opener.count >= 2 && closer.count >= 2 {
+				n, tag = 2, "strong"
+			}
+			opener.count -= n
+			closer.count -= n
+			opener.opens = append(opener.opens, "<"+tag+">")
+			closer.closes = append(closer.closes, "</"+tag+">")
+
+			// Delimiters between the pair can no longer match
+			prev[c] = o
+			if opener.count == 0 {
+				prev[c] = prev[o]
+			}
+		}
+		if closer.count == 0 {
+			remove(c)
+		}
+	}
+
+	// Closing tags come first, then the unused characters, then opening tags
+	var b strings.Builder
+	for _, piece := range pieces {
+		if piece.delim == nil {
+			b.WriteString(piece.text)
+			continue
+		}
+		d := piece.delim
+		for _, tag := range d.closes {
+			b.WriteString(tag)
+		}
+		b.WriteString(strings.Repeat(string(d.char), d.count))
+		for i := len(d.opens) - 1; i >= 0; i-- {
+			b.WriteString(d.opens[i])
+		}
+	}
+	return b.String()
+}
+
+// newDelimiter classifies the run text[start:end] using the CommonMark
+// left- and right-flanking rules; "_" never opens or closes inside a word
+func newDelimiter(text string, start, end int) *delimiter {
+	before, after := ' ', ' '
+	if start > 0 {
+		before, _ = utf8.DecodeLastRuneInString(text[:start])
+	}
+	if end < len(text) {
+		after, _ = utf8.DecodeRuneInString(text[end:])
+	}
+
+	leftFlanking := !unicode.IsSpace(after) &&
+		(!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+	rightFlanking := !unicode.IsSpace(before) &&
+		(!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+
+	d := &delimiter{char: text[start], length: end - start, count: end - start}
+	if d.char == '*' {
+		d.canOpen, d.canClose = leftFlanking, rightFlanking
+	} else {
+		d.canOpen = leftFlanking && (!rightFlanking || isPunct(before))
+		d.canClose = rightFlanking && (!leftFlanking || isPunct(after))
+	}
+	return d
+}
+
+// ruleOfThree reports whether a pair may not match because one run can both
+// open and close and the run lengths add up to a multiple of three
+func ruleOfThree(opener, closer *delimiter) bool {
+	if !(opener.canClose || closer.canOpen) {
+		return false
+	}
+	return (opener.length+closer.length)%3 == 0 && (opener.length%3 != 0 || closer.length%3 != 0)
+}
+
+// boolIndex returns 1 for true and 0 for false, for indexing by a flag
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// isPunct reports whether r is punctuation or a symbol for flanking purposes
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
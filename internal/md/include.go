@@ -0,0 +1,224 @@
+package md
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Include directives pull in another Markdown file or a named region of one:
+//
+//	<!-- include: snippets/warning.md -->
+//	<!-- include: snippets/prereqs.md#docker -->
+//
+// Regions are marked in the included file with
+//
+//	<!-- region: docker --> ... <!-- endregion: docker -->
+var (
+	includeRegex     = regexp.MustCompile(`^\s*<!--\s*include:\s*(\S+?)\s*-->\s*$`)
+	regionStartRegex = regexp.MustCompile(`^\s*<!--\s*region:\s*(\S+?)\s*-->\s*$`)
+	regionEndRegex   = regexp.MustCompile(`^\s*<!--\s*endregion(?::\s*(\S+?))?\s*-->\s*$`)
+)
+
+// fenceLineRegex matches a code fence: three or more backticks or tildes,
+// indented by at most three spaces, followed by the info string
+var fenceLineRegex = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+
+// codeFences follows the fenced code blocks of a file line by line, so the
+// include, conditional and variable passes agree on what is code
+type codeFences struct {
+	open string // fence of the current code block, empty outside one
+}
+
+// scan reports whether line belongs to a fenced code block, counting the
+// fence lines themselves. A block ends at a fence of the same character
+// that is at least as long as the one that opened it.
+func (f *codeFences) scan(line string) bool {
+	m := fenceLineRegex.FindStringSubmatch(line)
+	if f.open == "" {
+		// A backtick fence may not have backticks in its info string
+		if m != nil && !(m[1][0] == '`' && strings.Contains(m[2], "`")) {
+			f.open = m[1]
+			return true
+		}
+		return false
+	}
+	if m != nil && m[1][0] == f.open[0] && len(m[1]) >= len(f.open) && strings.TrimSpace(m[2]) == "" {
+		f.open = ""
+	}
+	return true
+}
+
+// readLines reads a Markdown file and expands its include directives. It
+// also returns the file every line came from.
+func (p *Parser) readLines(filePath string) ([]string, []string, error) {
+	return p.expandIncludes(filePath, "", filepath.Dir(filePath), []string{filePath})
+}
+
+// expandIncludes returns the lines of filePath (or one region of it) with
+// include directives replaced by the included content. Relative image and
+// link targets in included files are rewritten to stay valid from rootDir.
+// chain holds the files currently being expanded and is used to detect cycles.
+// The second result holds the source file of every returned line.
+func (p *Parser) expandIncludes(filePath, region, rootDir string, chain []string) ([]string, []string, error) {
+	lines, err := readFileLines(filePath)
+	if err != nil {
+		if len(chain) > 1 {
+			return nil, nil, fmt.Errorf("include failed (%s): %w", strings.Join(chain, " -> "), err)
+		}
+		return nil, nil, err
+	}
+
+	// Skip files whose front matter excludes the active profile
+	if ok, err := p.fileInProfile(lines, filePath); err != nil || !ok {
+		return nil, nil, err
+	}
+
+	// Drop conditional blocks outside the active profile
+	lines, lineNums, err := p.applyConditionals(lines, filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if region != "" {
+		if lines, lineNums, err = extractRegion(lines, lineNums, region); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+	}
+
+	// Substitute variables before sectioning so headings can use them too;
+	// only the included region has to define its variables
+	if lines, err = p.expandVariables(lines, lineNums, filePath); err != nil {
+		return nil, nil, err
+	}
+
+	included := len(chain) > 1
+	if included {
+		lines = stripFrontMatter(lines)
+	}
+
+	result := make([]string, 0, len(lines))
+	origins := make([]string, 0, len(lines))
+	var fences codeFences
+
+	for _, line := range lines {
+		inCodeBlock := fences.scan(line)
+
+		// Drop region markers from the output
+		if !inCodeBlock && (regionStartRegex.MatchString(line) || regionEndRegex.MatchString(line)) {
+			continue
+		}
+
+		matches := includeRegex.FindStringSubmatch(line)
+		if inCodeBlock || matches == nil {
+			if included {
+				line = rebaseTargets(line, filepath.Dir(filePath), rootDir)
+			}
+			result = append(result, line)
+			origins = append(origins, filePath)
+			continue
+		}
+
+		// Resolve the include relative to the including file
+		target, targetRegion, _ := strings.Cut(matches[1], "#")
+		targetPath := filepath.Join(filepath.Dir(filePath), filepath.FromSlash(target))
+
+		for _, seen := range chain {
+			if fileKey(seen) == fileKey(targetPath) {
+				return nil, nil, fmt.Errorf("include cycle: %s", strings.Join(append(chain, targetPath), " -> "))
+			}
+		}
+
+		includedLines, includedOrigins, err := p.expandIncludes(targetPath, targetRegion, rootDir, append(chain[:len(chain):len(chain)], targetPath))
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, includedLines...)
+		origins = append(origins, includedOrigins...)
+	}
+
+	return result, origins, nil
+}
+
+// readFileLines reads a file into a slice of lines
+func readFileLines(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// extractRegion returns the lines between the start and end markers of a
+// region, with their line numbers
+func extractRegion(lines []string, lineNums []int, region string) ([]string, []int, error) {
+	start := -1
+	var fences codeFences
+	for i, line := range lines {
+		if fences.scan(line) {
+			continue
+		}
+		if start < 0 {
+			if m := regionStartRegex.FindStringSubmatch(line); m != nil && m[1] == region {
+				start = i + 1
+			}
+			continue
+		}
+		if m := regionEndRegex.FindStringSubmatch(line); m != nil && (m[1] == "" || m[1] == region) {
+			return lines[start:i], lineNums[start:i], nil
+		}
+	}
+	if start < 0 {
+		return nil, nil, fmt.Errorf("region %q not found", region)
+	}
+	return nil, nil, fmt.Errorf("region %q is not closed", region)
+}
+
+// stripFrontMatter removes a leading --- front matter block
+func stripFrontMatter(lines []string) []string {
+	if len(lines) == 0 || lines[0] != "---" {
+		return lines
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == "---" {
+			return lines[i+1:]
+		}
+	}
+	return lines
+}
+
+// rebaseTargets rewrites relative link and image targets in a line from an
+// included file so they resolve from the including file's directory
+func rebaseTargets(line, fromDir, rootDir string) string {
+	return linkRegex.ReplaceAllStringFunc(line, func(match string) string {
+		parts := linkRegex.FindStringSubmatch(match)
+		u, err := url.Parse(parts[3])
+		if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+			return match
+		}
+
+		rel, err := filepath.Rel(rootDir, filepath.Join(fromDir, filepath.FromSlash(u.Path)))
+		if err != nil {
+			return match
+		}
+		target := filepath.ToSlash(rel)
+		if u.Fragment != "" {
+			target += "#" + u.Fragment
+		}
+		return formatLink(parts[1], parts[2], target, parts[4])
+	})
+}
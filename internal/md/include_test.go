@@ -0,0 +1,35 @@
+package md
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCodeFencesSkipDirectives(t *testing.T) {
+	// Every pass must leave the directives inside both code blocks alone
+	body := "# A\n\n" +
+		"  ~~~html\n<!-- include: missing.md -->\n<!-- if: admin -->\n{{ .Unknown }}\n  ~~~\n\n" +
+		"````md\n```\n<!-- endif -->\n```\n````\n\n" +
+		"Version {{ .Version }}\n"
+	want := []string{"<!-- include: missing.md -->", "<!-- if: admin -->", "{{ .Unknown }}", "<!-- endif -->", "Version 1.2"}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.md")
+	if err := os.WriteFile(file, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parser := NewParser(filepath.Join(dir, "out"))
+	parser.SetVariables(map[string]string{"Version": "1.2"})
+	parser.SetProfile("user")
+	doc, err := parser.ParseFiles([]string{file})
+	if err != nil {
+		t.Fatalf("ParseFiles: %v", err)
+	}
+	for _, text := range want {
+		if !strings.Contains(doc.Sections[0].Markdown, text) {
+			t.Errorf("markdown is missing %q:\n%s", text, doc.Sections[0].Markdown)
+		}
+	}
+}
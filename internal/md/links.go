@@ -0,0 +1,190 @@
+package md
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"docTrainerGO/internal/document"
+)
+
+// linkDestination matches the "(href "title")" part of links and images; it
+// is shared with the renderer so both agree on what a link is
+const linkDestination = `\(\s*([^)\s]+)(?:\s+"([^"]*)")?\s*\)`
+
+// linkRegex matches Markdown links and images; images are skipped when rewriting
+var linkRegex = regexp.MustCompile(`(!?)\[([^\]]*)\]` + linkDestination)
+
+// errNotInBuild reports a link to a Markdown file that exists but is not part
+// of the build (excluded or outside the directory); such links are left
+// unchanged
+var errNotInBuild = errors.New("file not part of the build")
+
+// fileAnchors records the generated section IDs of a single Markdown file
+type fileAnchors struct {
+	firstID  string            // ID of the first section, used for bare file links
+	headings map[string]string // heading slug -> section ID
+}
+
+// registerHeading records the anchor slug of a heading for later link
+// resolution, under the file the heading was written in. Headings pulled in
+// by an include directive are kept apart, so a file that is also a page of
+// its own links to that page.
+func (p *Parser) registerHeading(filePath, heading, sectionID string, included bool) {
+	files := p.anchors
+	if included {
+		files = p.included
+	}
+	key := fileKey(filePath)
+	anchors, ok := files[key]
+	if !ok {
+		anchors = &fileAnchors{firstID: sectionID, headings: make(map[string]string)}
+		files[key] = anchors
+	}
+
+	// Duplicate headings get -1, -2, ... suffixes like GitHub anchors
+	slug := slugify(heading)
+	candidate := slug
+	for n := 1; ; n++ {
+		if _, exists := anchors.headings[candidate]; !exists {
+			break
+		}
+		candidate = fmt.Sprintf("%s-%d", slug, n)
+	}
+	anchors.headings[candidate] = sectionID
+}
+
+// resolveLinks rewrites relative .md links and heading anchors in every section
+// to in-site section anchors and reports links whose target does not exist
+func (p *Parser) resolveLinks(sections []document.Section) error {
+	var broken []string
+	reported := make(map[string]bool)
+
+	for i := range sections {
+		section := &sections[i]
+		sourceFile := p.sectionFiles[section.ID]
+		rewrite := func(text string) string {
+			return linkRegex.ReplaceAllStringFunc(text, func(match string) string {
+				parts := linkRegex.FindStringSubmatch(match)
+				if parts[1] == "!" {
+					return match
+				}
+
+				target, ok, err := p.resolveTarget(sourceFile, parts[3])
+				if errors.Is(err, errNotInBuild) {
+					warning := fmt.Sprintf("%s (section %q): %v", sourceFile, section.Heading, err)
+					if !reported[warning] {
+						reported[warning] = true
+						fmt.Printf("  Warning: %s, link left unchanged\n", warning)
+					}
+					return match
+				}
+				if err != nil {
+					// Content and Markdown hold the same links; report each once
+					message := fmt.Sprintf("%s (section %q): %v", sourceFile, section.Heading, err)
+					if !reported[message] {
+						reported[message] = true
+						broken = append(broken, message)
+					}
+					return match
+				}
+				if !ok {
+					return match
+				}
+				return formatLink("", parts[2], "#"+target, parts[4])
+			})
+		}
+		section.Content = rewriteOutsideCode(section.Content, rewrite)
+		section.Markdown = rewriteOutsideCode(section.Markdown, rewrite)
+	}
+
+	if len(broken) > 0 {
+		return fmt.Errorf("%d broken link(s):\n  %s", len(broken), strings.Join(broken, "\n  "))
+	}
+	return nil
+}
+
+// resolveTarget maps a link target to a section ID. It returns ok=false for
+// links that are not in-site Markdown links (external URLs, images, etc.)
+func (p *Parser) resolveTarget(sourceFile, target string) (string, bool, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "" || u.Host != "" || strings.HasPrefix(target, "/") {
+		return "", false, nil
+	}
+
+	targetFile := sourceFile
+	if u.Path != "" {
+		if !strings.HasSuffix(strings.ToLower(u.Path), ".md") {
+			return "", false, nil
+		}
+		targetFile = filepath.Join(filepath.Dir(sourceFile), filepath.FromSlash(u.Path))
+	} else if u.Fragment == "" {
+		return "", false, nil
+	}
+
+	anchors, ok := p.anchors[fileKey(targetFile)]
+	if !ok {
+		anchors, ok = p.included[fileKey(targetFile)]
+	}
+	if !ok {
+		// Files that exist but are excluded only warn; missing files are broken
+		if _, err := os.Stat(targetFile); err != nil {
+			return "", false, fmt.Errorf("link target %s: file not found", target)
+		}
+		return "", false, fmt.Errorf("link target %s: %w", target, errNotInBuild)
+	}
+
+	if u.Fragment == "" {
+		return anchors.firstID, true, nil
+	}
+
+	sectionID, ok := anchors.headings[slugify(u.Fragment)]
+	if !ok {
+		return "", false, fmt.Errorf("link target %s: heading #%s not found", target, u.Fragment)
+	}
+	return sectionID, true, nil
+}
+
+// formatLink writes a Markdown link or image ("!" prefix), keeping its title
+func formatLink(prefix, text, target, title string) string {
+	if title != "" {
+		return fmt.Sprintf("%s[%s](%s \"%s\")", prefix, text, target, title)
+	}
+	return fmt.Sprintf("%s[%s](%s)", prefix, text, target)
+}
+
+// rewriteOutsideCode applies fn to the parts of content outside ``` code blocks
+func rewriteOutsideCode(content string, fn func(string) string) string {
+	parts := strings.Split(content, "```")
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = fn(parts[i])
+	}
+	return strings.Join(parts, "```")
+}
+
+// slugify converts a heading into a GitHub-style anchor slug
+func slugify(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// fileKey normalizes a file path for use as a map key
+func fileKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,52 @@
+package md
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		link    string
+		wantErr string // empty when the build succeeds
+		want    string // Markdown link after resolution
+	}{
+		{name: "heading in another file", link: "[x](b.md#second)", want: "[x](#section-3)"},
+		{name: "excluded file on disk", link: "[x](excluded.md)", want: "[x](excluded.md)"},
+		{name: "missing file", link: "[x](missing.md)", wantErr: "missing.md: file not found"},
+		{name: "missing heading", link: "[x](b.md#nowhere)", wantErr: "heading #nowhere not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range map[string]string{
+				"a.md":        "# A\n\nSee " + tt.link + ".\n",
+				"b.md":        "# B\n\ntext\n\n## Second\n\nmore\n",
+				"excluded.md": "# Excluded\n",
+			} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			parser := NewParser(filepath.Join(dir, "out"))
+			doc, err := parser.ParseFiles([]string{filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md")})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseFiles error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFiles: %v", err)
+			}
+			if !strings.Contains(doc.Sections[0].Markdown, tt.want) {
+				t.Errorf("markdown = %q, want it to contain %q", doc.Sections[0].Markdown, tt.want)
+			}
+		})
+	}
+}
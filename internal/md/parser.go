@@ -1,42 +1,55 @@
 package md
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
-	"docTrainerGO/internal/pdf"
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/highlight"
 )
 
+// imageRefRegex matches ![alt](path) and ![alt](path "title")
+var imageRefRegex = regexp.MustCompile(`!\[([^\]]*)\]` + linkDestination)
+
 // Parser handles Markdown file parsing
 type Parser struct {
-	outputDir string
-	imageDir  string
-	sectionID int
+	outputDir    string
+	imageDir     string
+	sectionID    int
+	anchors      map[string]*fileAnchors // source file -> heading anchors
+	included     map[string]*fileAnchors // included file -> anchors where it was included
+	sectionFiles map[string]string       // section ID -> source file
+	images       *document.ImageStore
+	variables    map[string]string // values for {{ .Name }} placeholders
+	profile      string            // active audience profile, empty keeps everything
 }
 
 // NewParser creates a new Markdown parser
 func NewParser(outputDir string) *Parser {
 	return &Parser{
-		outputDir: outputDir,
-		imageDir:  filepath.Join(outputDir, "images"),
-		sectionID: 0,
+		outputDir:    outputDir,
+		imageDir:     filepath.Join(outputDir, "images"),
+		sectionID:    0,
+		anchors:      make(map[string]*fileAnchors),
+		included:     make(map[string]*fileAnchors),
+		sectionFiles: make(map[string]string),
+		images:       document.NewImageStore(filepath.Join(outputDir, "images")),
 	}
 }
 
 // ParseFiles processes multiple markdown files
-func (p *Parser) ParseFiles(files []string) (*pdf.Document, error) {
+func (p *Parser) ParseFiles(files []string) (*document.Document, error) {
 	// Ensure image directory exists
 	if err := os.MkdirAll(p.imageDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create image directory: %w", err)
 	}
 
-	doc := &pdf.Document{
+	doc := &document.Document{
 		Title:    "Documentation",
-		Sections: make([]pdf.Section, 0),
+		Sections: make([]document.Section, 0),
 	}
 
 	// Process each markdown file
@@ -48,34 +61,65 @@ func (p *Parser) ParseFiles(files []string) (*pdf.Document, error) {
 		doc.Sections = append(doc.Sections, sections...)
 	}
 
+	// Rewrite cross-file links now that every heading has an ID
+	if err := p.resolveLinks(doc.Sections); err != nil {
+		return nil, err
+	}
+
+	// Locate inline images and pre-render the final section content
+	for i := range doc.Sections {
+		locateImages(&doc.Sections[i])
+		doc.Sections[i].HTML = RenderHTML(doc.Sections[i].Markdown)
+	}
+
+	// Record where every stored image came from
+	doc.Images = p.images.Assets()
+
 	return doc, nil
 }
 
 // parseFile parses a single markdown file
-func (p *Parser) parseFile(filePath string) ([]pdf.Section, error) {
-	file, err := os.Open(filePath)
+func (p *Parser) parseFile(filePath string) ([]document.Section, error) {
+	// Read the file with include directives expanded
+	lines, origins, err := p.readLines(filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	sections := make([]pdf.Section, 0)
-	scanner := bufio.NewScanner(file)
+	sections := make([]document.Section, 0)
 
-	var currentSection *pdf.Section
+	var currentSection *document.Section
 	var contentBuilder strings.Builder
+	var markdownBuilder strings.Builder
 	var inCodeBlock bool
+	var codeBlock *document.CodeBlock
+	var codeLines []string
 	var inFrontMatter bool
 	lineNum := 0
 
 	// Regular expressions - using raw strings
 	headingRegex := regexp.MustCompile("^(#{1,6})\\s+(.+)$")
-	imageRegex := regexp.MustCompile("!\\[([^\\]]*)\\]\\(([^)]+)\\)")
 	codeBlockRegex := regexp.MustCompile("^```")
 	frontMatterRegex := regexp.MustCompile("^---$")
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	// flushCallout writes a finished admonition into the current section
+	var callout *admonition
+	flushCallout := func() {
+		if callout == nil || currentSection == nil {
+			callout = nil
+			return
+		}
+		for i, line := range callout.lines {
+			callout.lines[i] = p.rewriteImages(currentSection, line, filePath)
+		}
+		contentBuilder.WriteString(callout.marker())
+		contentBuilder.WriteString(" ")
+		markdownBuilder.WriteString(callout.block())
+		currentSection.Admonitions = append(currentSection.Admonitions, callout.toSection())
+		callout = nil
+	}
+
+	for _, line := range lines {
 		lineNum++
 
 		// Handle front matter
@@ -90,27 +134,63 @@ func (p *Parser) parseFile(filePath string) ([]pdf.Section, error) {
 			continue
 		}
 
-		// Handle code blocks
+		// Collect admonition blocks
+		if callout != nil {
+			consumed, closed := callout.add(line)
+			if closed {
+				if callout.unclosed {
+					fmt.Printf("  Warning: %s:%d: admonition :::%s is not closed, ending it at the heading\n", filePath, lineNum, callout.kind)
+				}
+				flushCallout()
+			}
+			if consumed {
+				continue
+			}
+		}
+
+		// Handle code blocks; the info string is reduced to its language
 		if codeBlockRegex.MatchString(line) {
 			inCodeBlock = !inCodeBlock
+			if inCodeBlock {
+				codeBlock = &document.CodeBlock{Language: highlight.Normalize(strings.TrimPrefix(line, "```"))}
+				codeLines = nil
+				line = "```" + codeBlock.Language
+			} else if currentSection != nil {
+				codeBlock.Code = strings.Join(codeLines, "\n")
+				currentSection.CodeBlocks = append(currentSection.CodeBlocks, *codeBlock)
+			}
 			contentBuilder.WriteString(line)
 			contentBuilder.WriteString("\n")
+			markdownBuilder.WriteString(line)
+			markdownBuilder.WriteString("\n")
 			continue
 		}
 
 		if inCodeBlock {
+			codeLines = append(codeLines, line)
 			contentBuilder.WriteString(line)
 			contentBuilder.WriteString("\n")
+			markdownBuilder.WriteString(line)
+			markdownBuilder.WriteString("\n")
 			continue
 		}
 
+		// Check for the start of an admonition
+		if currentSection != nil {
+			if callout = startAdmonition(line); callout != nil {
+				continue
+			}
+		}
+
 		// Check for heading
 		if matches := headingRegex.FindStringSubmatch(line); matches != nil {
 			// Save previous section
 			if currentSection != nil {
 				currentSection.Content = strings.TrimSpace(contentBuilder.String())
+				currentSection.Markdown = strings.TrimSpace(markdownBuilder.String())
 				sections = append(sections, *currentSection)
 				contentBuilder.Reset()
+				markdownBuilder.Reset()
 			}
 
 			// Create new section
@@ -118,85 +198,124 @@ func (p *Parser) parseFile(filePath string) ([]pdf.Section, error) {
 			level := len(matches[1])
 			heading := strings.TrimSpace(matches[2])
 
-			currentSection = &pdf.Section{
+			currentSection = &document.Section{
 				ID:      fmt.Sprintf("section-%d", p.sectionID),
 				Level:   level,
 				Heading: heading,
-				Images:  make([]string, 0),
+				Images:  make([]document.Image, 0),
+				Source:  filePath,
 			}
+			// Headings are anchored in the file they were written in;
+			// relative links resolve from the file that includes them
+			p.registerHeading(origins[lineNum-1], heading, currentSection.ID, origins[lineNum-1] != filePath)
+			p.sectionFiles[currentSection.ID] = filePath
 			continue
 		}
 
-		// Extract images from line
-		if imageMatches := imageRegex.FindAllStringSubmatch(line, -1); imageMatches != nil {
-			for _, match := range imageMatches {
-				imagePath := match[2]
-				if currentSection != nil {
-					// Copy image to output directory
-					if err := p.copyImage(imagePath, filePath); err == nil {
-						imageName := filepath.Base(imagePath)
-						currentSection.Images = append(currentSection.Images, imageName)
-					}
-				}
-			}
+		// Extract images from line and point them at their stored names
+		if currentSection != nil {
+			line = p.rewriteImages(currentSection, line, filePath)
 		}
 
 		// Add line to content
 		if currentSection != nil {
 			contentBuilder.WriteString(line)
 			contentBuilder.WriteString(" ")
+			markdownBuilder.WriteString(line)
+			markdownBuilder.WriteString("\n")
 		}
 	}
 
 	// Save last section
+	if callout != nil && callout.fenced {
+		fmt.Printf("  Warning: %s: admonition :::%s is not closed, ending it at the end of the file\n", filePath, callout.kind)
+	}
+	flushCallout()
 	if currentSection != nil {
 		currentSection.Content = strings.TrimSpace(contentBuilder.String())
+		currentSection.Markdown = strings.TrimSpace(markdownBuilder.String())
 		sections = append(sections, *currentSection)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
 	return sections, nil
 }
 
-// copyImage copies an image from source to output directory
-func (p *Parser) copyImage(imagePath string, markdownFile string) error {
+// rewriteImages copies the images referenced in line to the output directory,
+// records them on the section and points the references at the stored names
+func (p *Parser) rewriteImages(section *document.Section, line string, filePath string) string {
+	return imageRefRegex.ReplaceAllStringFunc(line, func(match string) string {
+		parts := imageRefRegex.FindStringSubmatch(match)
+		imageName, err := p.copyImage(parts[2], filePath)
+		if err != nil {
+			return match
+		}
+		section.Images = append(section.Images, document.Image{
+			Src:   imageName,
+			Alt:   parts[1],
+			Title: parts[3],
+		})
+		if parts[3] != "" {
+			return fmt.Sprintf("![%s](images/%s %q)", parts[1], imageName, parts[3])
+		}
+		return fmt.Sprintf("![%s](images/%s)", parts[1], imageName)
+	})
+}
+
+// copyImage copies an image to the output directory under a content-hash
+// name and returns that name. Identical images are stored only once.
+func (p *Parser) copyImage(imagePath string, markdownFile string) (string, error) {
 	// Resolve relative paths
 	baseDir := filepath.Dir(markdownFile)
 	sourcePath := filepath.Join(baseDir, imagePath)
 
 	// Check if source exists
 	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-		return fmt.Errorf("image not found: %s", sourcePath)
+		return "", fmt.Errorf("image not found: %s", sourcePath)
 	}
 
 	// Read source
 	data, err := os.ReadFile(sourcePath)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	// Write to destination
-	imageName := filepath.Base(imagePath)
-	destPath := filepath.Join(p.imageDir, imageName)
+	return p.images.Save(data, filepath.Ext(sourcePath), sourcePath)
+}
 
-	if err := os.WriteFile(destPath, data, 0644); err != nil {
-		return err
+// locateImages sets the position of each image to the offset of its
+// reference in the section content
+func locateImages(section *document.Section) {
+	next := 0
+	for _, loc := range imageRefRegex.FindAllStringSubmatchIndex(section.Content, -1) {
+		if next >= len(section.Images) {
+			break
+		}
+		path := section.Content[loc[4]:loc[5]]
+		if path == "images/"+section.Images[next].Src {
+			section.Images[next].Position = loc[0]
+			next++
+		}
+	}
+	for ; next < len(section.Images); next++ {
+		section.Images[next].Position = -1
 	}
-
-	return nil
 }
 
 // ParseDirectory processes all markdown files in a directory
-func (p *Parser) ParseDirectory(dir string) (*pdf.Document, error) {
+func (p *Parser) ParseDirectory(dir string) (*document.Document, error) {
 	var files []string
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		// Names starting with "_" hold include snippets, not pages
+		if path != dir && strings.HasPrefix(info.Name(), "_") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if !info.IsDir() && strings.HasSuffix(info.Name(), ".md") && info.Name() != "README.md" {
 			files = append(files, path)
 		}
@@ -0,0 +1,268 @@
+package md
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"docTrainerGO/internal/highlight"
+)
+
+// Block-level patterns used by RenderHTML
+var (
+	fenceRegex       = regexp.MustCompile("^\\s*(```+)\\s*(.*)$")
+	calloutRegex     = regexp.MustCompile(`^:::(\w+)\s*(.*)$`)
+	bulletRegex      = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedRegex     = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	quoteRegex       = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	ruleRegex        = regexp.MustCompile(`^\s*([-*_]\s*){3,}$`)
+	tableSepRegex    = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	htmlCommentRegex = regexp.MustCompile(`^\s*<!--.*-->\s*$`)
+)
+
+// Inline patterns: code spans, images and links are matched together so
+// their contents are never reprocessed
+var (
+	inlineRegex = regexp.MustCompile("`([^`]+)`" +
+		`|!\[([^\]]*)\]` + linkDestination +
+		`|\[([^\]]+)\]` + linkDestination)
+	// escapeRegex matches a backslash-escaped ASCII punctuation character
+	escapeRegex = regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])")
+)
+
+// escapeBase is the private-use rune standing in for escaped character 0;
+// escaped characters are hidden as escapeBase+c while inline markup is parsed
+const escapeBase = '\uE000'
+
+// RenderHTML converts the Markdown body of a section to HTML. Fenced code
+// blocks are syntax highlighted at build time.
+func RenderHTML(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	var b strings.Builder
+	var paragraph []string
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>")
+			b.WriteString(renderInline(strings.Join(paragraph, " ")))
+			b.WriteString("</p>\n")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "" || htmlCommentRegex.MatchString(line):
+			flushParagraph()
+
+		case fenceRegex.MatchString(line):
+			flushParagraph()
+			m := fenceRegex.FindStringSubmatch(line)
+			lang := highlight.Normalize(m[2])
+			var code []string
+			for i++; i < len(lines) && !closesFence(lines[i], m[1]); i++ {
+				code = append(code, lines[i])
+			}
+			b.WriteString(renderCodeBlock(lang, strings.Join(code, "\n")))
+
+		case calloutRegex.MatchString(trimmed):
+			flushParagraph()
+			m := calloutRegex.FindStringSubmatch(trimmed)
+			var body []string
+			fence := "" // fence of the code block the body is in
+			for i++; i < len(lines); i++ {
+				if fence != "" {
+					if closesFence(lines[i], fence) {
+						fence = ""
+					}
+				} else if fm := fenceRegex.FindStringSubmatch(lines[i]); fm != nil {
+					fence = fm[1]
+				}
+				if fence == "" && strings.TrimSpace(lines[i]) == ":::" {
+					break
+				}
+				body = append(body, lines[i])
+			}
+			b.WriteString(renderCallout(strings.ToLower(m[1]), m[2], strings.Join(body, "\n")))
+
+		case ruleRegex.MatchString(line):
+			flushParagraph()
+			b.WriteString("<hr>\n")
+
+		case bulletRegex.MatchString(line), orderedRegex.MatchString(line):
+			flushParagraph()
+			tag, pattern := "ul", bulletRegex
+			if !bulletRegex.MatchString(line) {
+				tag, pattern = "ol", orderedRegex
+			}
+			b.WriteString("<" + tag + ">\n")
+			for ; i < len(lines) && pattern.MatchString(lines[i]); i++ {
+				item := pattern.FindStringSubmatch(lines[i])[1]
+				b.WriteString("<li>" + renderInline(item) + "</li>\n")
+			}
+			i--
+			b.WriteString("</" + tag + ">\n")
+
+		case quoteRegex.MatchString(line):
+			flushParagraph()
+			var quote []string
+			for ; i < len(lines) && quoteRegex.MatchString(lines[i]); i++ {
+				quote = append(quote, quoteRegex.FindStringSubmatch(lines[i])[1])
+			}
+			i--
+			b.WriteString("<blockquote>\n" + RenderHTML(strings.Join(quote, "\n")) + "</blockquote>\n")
+
+		case strings.Contains(line, "|") && i+1 < len(lines) && tableSepRegex.MatchString(lines[i+1]):
+			flushParagraph()
+			var rows []string
+			for ; i < len(lines) && strings.Contains(lines[i], "|"); i++ {
+				rows = append(rows, lines[i])
+			}
+			i--
+			b.WriteString(renderTable(rows))
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flushParagraph()
+
+	return b.String()
+}
+
+// closesFence reports whether line ends a code block opened with fence: a
+// run of at least as many backticks and nothing else
+func closesFence(line, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	return len(trimmed) >= len(fence) && strings.Trim(trimmed, "`") == ""
+}
+
+// renderCodeBlock renders a highlighted fenced code block
+func renderCodeBlock(lang, code string) string {
+	class := ""
+	if lang != "" {
+		class = fmt.Sprintf(` class="language-%s"`, html.EscapeString(lang))
+	}
+	return fmt.Sprintf("<pre><code%s>%s</code></pre>\n", class, highlight.Highlight(lang, code))
+}
+
+// renderCallout renders an admonition block
+func renderCallout(kind, title, body string) string {
+	if title == "" {
+		title = strings.ToUpper(kind[:1]) + kind[1:]
+	}
+	return fmt.Sprintf("<div class=\"admonition admonition-%s\" role=\"note\">\n<p class=\"admonition-title\">%s</p>\n%s</div>\n",
+		html.EscapeString(kind), html.EscapeString(title), RenderHTML(body))
+}
+
+// renderTable renders a pipe table; rows[1] is the separator row
+func renderTable(rows []string) string {
+	var b strings.Builder
+	b.WriteString("<table>\n<thead>\n")
+	writeTableRow(&b, rows[0], "th")
+	b.WriteString("</thead>\n<tbody>\n")
+	for _, row := range rows[2:] {
+		writeTableRow(&b, row, "td")
+	}
+	b.WriteString("</tbody>\n</table>\n")
+	return b.String()
+}
+
+// writeTableRow writes one table row with the given cell tag
+func writeTableRow(b *strings.Builder, row, cellTag string) {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	row = strings.TrimSuffix(row, "|")
+	b.WriteString("<tr>")
+	for _, cell := range splitTableRow(row) {
+		b.WriteString("<" + cellTag + ">" + renderInline(strings.TrimSpace(cell)) + "</" + cellTag + ">")
+	}
+	b.WriteString("</tr>\n")
+}
+
+// splitTableRow splits a table row on unescaped pipes; `\|` is a literal pipe
+func splitTableRow(row string) []string {
+	cells := strings.Split(strings.ReplaceAll(row, `\|`, "\x00"), "|")
+	for i, cell := range cells {
+		cells[i] = strings.ReplaceAll(cell, "\x00", "|")
+	}
+	return cells
+}
+
+// renderInline renders code spans, images, links and emphasis. A backslash
+// before ASCII punctuation makes the character literal.
+func renderInline(text string) string {
+	text = escapeRegex.ReplaceAllStringFunc(text, func(match string) string {
+		return string(escapeBase + rune(match[1]))
+	})
+
+	var b strings.Builder
+	last := 0
+
+	for _, m := range inlineRegex.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(renderEmphasis(html.EscapeString(text[last:m[0]])))
+		last = m[1]
+
+		group := func(n int) string {
+			if m[2*n] < 0 {
+				return ""
+			}
+			return text[m[2*n]:m[2*n+1]]
+		}
+
+		switch {
+		case m[2] >= 0: // `code`
+			b.WriteString("<code>" + restoreEscapes(html.EscapeString(group(1)), `\`) + "</code>")
+		case m[6] >= 0: // ![alt](src "title")
+			b.WriteString(renderImage(group(2), group(3), group(4)))
+		default: // [text](href "title")
+			b.WriteString(renderLink(group(5), group(6), group(7)))
+		}
+	}
+	b.WriteString(renderEmphasis(html.EscapeString(text[last:])))
+
+	return restoreEscapes(b.String(), "")
+}
+
+// restoreEscapes turns hidden escaped characters in rendered HTML back into
+// escaped text, prefixed with prefix; code spans keep their backslashes
+func restoreEscapes(text, prefix string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if r >= escapeBase && r < escapeBase+128 {
+			b.WriteString(html.EscapeString(prefix + string(r-escapeBase)))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// renderImage renders an image; titled images become figures with a caption.
+// Stored images keep their "images/..." path relative to the output directory.
+func renderImage(alt, src, title string) string {
+	img := fmt.Sprintf(`<img src="%s" alt="%s" class="inline-image" loading="lazy">`,
+		html.EscapeString(src), html.EscapeString(alt))
+	if title == "" {
+		return img
+	}
+	return fmt.Sprintf(`<figure class="inline-figure">%s<figcaption>%s</figcaption></figure>`, img, html.EscapeString(title))
+}
+
+// renderLink renders a link; in-site section anchors stay in the page
+func renderLink(text, href, title string) string {
+	attrs := fmt.Sprintf(`href="%s"`, html.EscapeString(href))
+	if title != "" {
+		attrs += fmt.Sprintf(` title="%s"`, html.EscapeString(title))
+	}
+	if strings.HasPrefix(href, "#") {
+		attrs += ` class="section-link"`
+	} else {
+		attrs += ` target="_blank" rel="noopener"`
+	}
+	return fmt.Sprintf("<a %s>%s</a>", attrs, renderInline(text))
+}
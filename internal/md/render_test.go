@@ -0,0 +1,93 @@
+package md
+
+import (
+	"html"
+	"strings"
+	"testing"
+
+	"docTrainerGO/internal/document"
+)
+
+func TestRenderInlineEmphasis(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"**bold** and *em*", "<strong>bold</strong> and <em>em</em>"},
+		{"__bold__ and _em_", "<strong>bold</strong> and <em>em</em>"},
+		{"***both***", "<em><strong>both</strong></em>"},
+		{"**a *b** c*", "<em><em>a <em>b</em></em> c</em>"},
+		{"*source-name***_***binary-version***_***arch***",
+			"<em>source-name</em>**<em><em><strong>binary-version</strong></em></em><em><strong>arch</strong></em>"},
+		{"snake_case_name", "snake_case_name"},
+		{"2 * 3 * 4", "2 * 3 * 4"},
+		{"*unclosed", "*unclosed"},
+		{`\*literal\* and \_x\_`, "*literal* and _x_"},
+		{`a \[b\] \| c`, "a [b] | c"},
+		{"`a\\*b` *c*", "<code>a\\*b</code> <em>c</em>"},
+		{"[*link*](x.html)", `<a href="x.html" target="_blank" rel="noopener"><em>link</em></a>`},
+	}
+
+	for _, tt := range tests {
+		if got := renderInline(tt.in); got != tt.want {
+			t.Errorf("renderInline(%q)\n got: %s\nwant: %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []string{
+		"snake_case and 2*3",
+		"[not a link](x.md)",
+		"a | b `c` d",
+		`C:\Users\*name*`,
+		"**already** plain",
+		`trailing\`,
+	}
+
+	for _, text := range tests {
+		if got := renderInline(EscapeText(text)); got != html.EscapeString(text) {
+			t.Errorf("renderInline(EscapeText(%q)) = %q, want the text unchanged", text, got)
+		}
+	}
+
+	// Escaped text ending in a backslash must not escape markup after it
+	if got := renderInline(EscapeText(`C:\`) + "*x*"); got != `C:\<em>x</em>` {
+		t.Errorf("trailing backslash escaped the following markup: %q", got)
+	}
+}
+
+func TestRenderHTMLFenceLength(t *testing.T) {
+	markdown := "````text\n```go\nx := 1\n```\n````\n\nafter"
+	got := RenderHTML(markdown)
+	if !strings.Contains(got, "```go") || !strings.Contains(got, "<p>after</p>") {
+		t.Errorf("RenderHTML did not keep the inner fence in the code block:\n%s", got)
+	}
+
+	section := document.Section{Markdown: markdown}
+	FinishSection(&section)
+	if len(section.CodeBlocks) != 1 || section.CodeBlocks[0].Code != "```go\nx := 1\n```" {
+		t.Errorf("FinishSection code blocks = %+v, want the whole inner block", section.CodeBlocks)
+	}
+}
+
+// unmatchedEmphasis returns text where every "_" closer would scan back over
+// all "*" openers without a bound on the search
+func unmatchedEmphasis() string {
+	return strings.Repeat("*a ", 20000) + strings.Repeat("b_ ", 20000)
+}
+
+func TestRenderEmphasisUnmatched(t *testing.T) {
+	text := unmatchedEmphasis()
+	if got := renderEmphasis(text); got != text {
+		t.Errorf("unmatched delimiters were changed: %.60s", got)
+	}
+}
+
+func BenchmarkRenderEmphasisUnmatched(b *testing.B) {
+	text := unmatchedEmphasis()
+	b.SetBytes(int64(len(text)))
+	for i := 0; i < b.N; i++ {
+		renderEmphasis(text)
+	}
+}
@@ -0,0 +1,101 @@
+package md
+
+import (
+	"strings"
+
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/highlight"
+)
+
+// FinishSection derives the Content, images, code blocks, admonitions and
+// HTML of a section from its Markdown body. Sources for other formats build
+// the Markdown themselves and call it so they produce the same section model
+// as the Markdown parser. Image references must already point at stored
+// images ("images/<name>").
+func FinishSection(section *document.Section) {
+	var content strings.Builder
+	var codeBlock *document.CodeBlock
+	var codeLines []string
+	var fence string // backticks that opened the current code block
+	var callout *admonition
+
+	for _, line := range strings.Split(section.Markdown, "\n") {
+		// Collect fenced admonitions
+		if callout != nil {
+			consumed, closed := callout.add(line)
+			if closed {
+				content.WriteString(callout.marker())
+				content.WriteString(" ")
+				section.Admonitions = append(section.Admonitions, callout.toSection())
+				callout = nil
+			}
+			if consumed {
+				continue
+			}
+		}
+
+		// Code blocks keep their line breaks
+		if m := fenceRegex.FindStringSubmatch(line); m != nil && (codeBlock == nil || closesFence(line, fence)) {
+			if codeBlock == nil {
+				fence = m[1]
+				codeBlock = &document.CodeBlock{Language: highlight.Normalize(m[2])}
+				codeLines = nil
+			} else {
+				codeBlock.Code = strings.Join(codeLines, "\n")
+				section.CodeBlocks = append(section.CodeBlocks, *codeBlock)
+				codeBlock = nil
+			}
+			content.WriteString(line)
+			content.WriteString("\n")
+			continue
+		}
+		if codeBlock != nil {
+			codeLines = append(codeLines, line)
+			content.WriteString(line)
+			content.WriteString("\n")
+			continue
+		}
+
+		if m := fenceStartRegex.FindStringSubmatch(line); m != nil {
+			callout = &admonition{kind: strings.ToLower(m[1]), title: strings.TrimSpace(m[2]), fenced: true}
+			continue
+		}
+
+		content.WriteString(line)
+		content.WriteString(" ")
+	}
+	section.Content = strings.TrimSpace(content.String())
+
+	// Record the stored images the section references
+	if section.Images == nil {
+		section.Images = make([]document.Image, 0)
+		for _, m := range imageRefRegex.FindAllStringSubmatch(section.Markdown, -1) {
+			if name, ok := strings.CutPrefix(m[2], "images/"); ok {
+				section.Images = append(section.Images, document.Image{Src: name, Alt: m[1], Title: m[3]})
+			}
+		}
+	}
+	locateImages(section)
+
+	section.HTML = RenderHTML(section.Markdown)
+}
+
+// EscapeText escapes the characters of plain text that Markdown would read
+// as emphasis, code spans, links or table cell separators. Sources for other
+// formats use it for document text before adding their own markup.
+func EscapeText(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case strings.IndexByte("*_`[]|", c) >= 0:
+			b.WriteByte('\\')
+		case c == '\\' && (i+1 == len(text) || escapeRegex.MatchString(text[i:i+2])):
+			// A backslash would escape the punctuation after it, or the
+			// markup the text is joined with
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
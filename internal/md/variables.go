@@ -0,0 +1,69 @@
+package md
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// variableRegex matches {{ .Name }} placeholders; a leading backslash
+// (\{{ .Name }}) keeps the placeholder as literal text
+var variableRegex = regexp.MustCompile(`\\?\{\{\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// SetVariables sets the values substituted for {{ .Name }} placeholders
+func (p *Parser) SetVariables(vars map[string]string) {
+	p.variables = vars
+}
+
+// expandVariables substitutes placeholders in the lines of filePath and
+// reports every unknown variable with its original line number. Fenced code
+// blocks and inline code spans are left unchanged.
+func (p *Parser) expandVariables(lines []string, lineNums []int, filePath string) ([]string, error) {
+	var unknown []string
+	var fences codeFences
+
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		if fences.scan(line) {
+			result[i] = line
+			continue
+		}
+
+		// Even parts of a line split on backticks are outside code spans
+		parts := strings.Split(line, "`")
+		for j := 0; j < len(parts); j += 2 {
+			parts[j] = variableRegex.ReplaceAllStringFunc(parts[j], func(match string) string {
+				if strings.HasPrefix(match, `\`) {
+					return match[1:]
+				}
+				name := variableRegex.FindStringSubmatch(match)[1]
+				value, ok := p.variables[name]
+				if !ok {
+					unknown = append(unknown, fmt.Sprintf("%s:%d: unknown variable %q", filePath, lineNums[i], name))
+					return match
+				}
+				return value
+			})
+		}
+		result[i] = strings.Join(parts, "`")
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%s (defined: %s)", strings.Join(unknown, "; "), p.definedVariables())
+	}
+	return result, nil
+}
+
+// definedVariables lists the configured variable names for error messages
+func (p *Parser) definedVariables() string {
+	if len(p.variables) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(p.variables))
+	for name := range p.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,447 @@
+package notebook
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/md"
+	"docTrainerGO/internal/pdf"
+)
+
+var (
+	// headingRegex matches ATX headings in Markdown cells
+	headingRegex = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+	// imageRegex matches Markdown images: ![alt](src "title")
+	imageRegex = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)([^)]*)\)`)
+	// ansiRegex matches the terminal color codes in tracebacks and logs
+	ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+)
+
+// multiline is notebook text, stored either as a string or as a list of lines
+type multiline string
+
+// UnmarshalJSON accepts both forms of notebook text
+func (m *multiline) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*m = multiline(s)
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return err
+	}
+	*m = multiline(strings.Join(lines, ""))
+	return nil
+}
+
+// notebook is an nbformat 4 notebook
+type notebook struct {
+	Cells    []cell `json:"cells"`
+	Metadata struct {
+		Title      string `json:"title"`
+		KernelSpec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+	NBFormat int `json:"nbformat"`
+}
+
+// cell is a Markdown, code or raw cell
+type cell struct {
+	CellType    string                          `json:"cell_type"`
+	Source      multiline                       `json:"source"`
+	Outputs     []output                        `json:"outputs"`
+	Attachments map[string]map[string]multiline `json:"attachments"`
+}
+
+// output is one output of a code cell
+type output struct {
+	OutputType string                     `json:"output_type"`
+	Text       multiline                  `json:"text"`
+	Data       map[string]json.RawMessage `json:"data"`
+	EName      string                     `json:"ename"`
+	EValue     string                     `json:"evalue"`
+	Traceback  []string                   `json:"traceback"`
+}
+
+// Parser reads Jupyter notebooks. Markdown cells are split into sections at
+// their headings; code cells become code blocks followed by their outputs.
+type Parser struct {
+	images    *pdf.Parser // saves image outputs to the images directory
+	assets    []document.ImageAsset
+	assetIdx  map[string]int // image name -> index in assets
+	sectionID int
+}
+
+// NewParser creates a new notebook parser
+func NewParser(outputDir string) *Parser {
+	return &Parser{
+		images:    pdf.NewParser(outputDir),
+		assetIdx:  make(map[string]int),
+		sectionID: 0,
+	}
+}
+
+// ParseFiles parses several notebooks into one document
+func (p *Parser) ParseFiles(files []string) (*document.Document, error) {
+	doc := &document.Document{
+		Title:    "Notebooks",
+		Sections: make([]document.Section, 0),
+	}
+
+	for i, file := range files {
+		title, sections, err := p.parseFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		if i == 0 && title != "" {
+			doc.Title = title
+		}
+		doc.Sections = append(doc.Sections, sections...)
+	}
+
+	for i := range doc.Sections {
+		md.FinishSection(&doc.Sections[i])
+	}
+	doc.Images = p.assets
+	return doc, nil
+}
+
+// ParseDirectory parses all .ipynb files in a directory
+func (p *Parser) ParseDirectory(dir string) (*document.Document, error) {
+	var files []string
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// Jupyter keeps autosaved copies in .ipynb_checkpoints
+		if info.IsDir() && info.Name() == ".ipynb_checkpoints" {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && strings.HasSuffix(strings.ToLower(info.Name()), ".ipynb") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no notebooks found in %s", dir)
+	}
+
+	fmt.Printf("Found %d notebooks\n", len(files))
+	return p.ParseFiles(files)
+}
+
+// fileParser holds the state for parsing one notebook
+type fileParser struct {
+	*Parser
+	path     string
+	language string
+	sections []document.Section
+	current  *document.Section
+	lines    []string // Markdown of the current section
+	intro    string   // heading for content before the first heading
+	topTitle string   // first level 1 heading
+}
+
+// parseFile parses one notebook and returns its title and sections
+func (p *Parser) parseFile(filePath string) (string, []document.Section, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var nb notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return "", nil, fmt.Errorf("invalid notebook: %w", err)
+	}
+	if nb.NBFormat < 4 {
+		return "", nil, fmt.Errorf("unsupported notebook format %d (nbformat 4 is required)", nb.NBFormat)
+	}
+
+	// The kernel language labels the code blocks
+	language := nb.Metadata.LanguageInfo.Name
+	if language == "" {
+		language = nb.Metadata.KernelSpec.Language
+	}
+	if language == "" {
+		language = "python"
+	}
+
+	f := &fileParser{Parser: p, path: filePath, language: strings.ToLower(language)}
+	f.intro = nb.Metadata.Title
+	if f.intro == "" {
+		base := filepath.Base(filePath)
+		f.intro = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	for i, c := range nb.Cells {
+		origin := fmt.Sprintf("%s#cell-%d", filePath, i+1)
+		switch c.CellType {
+		case "markdown":
+			f.markdownCell(c, origin)
+		case "code":
+			f.codeCell(c, origin)
+		}
+		// Raw cells are meant for export tools and are left out
+	}
+	f.flush()
+
+	// The notebook title is its metadata title or first top-level heading
+	title := nb.Metadata.Title
+	if title == "" {
+		title = f.topTitle
+	}
+
+	return title, f.sections, nil
+}
+
+// markdownCell adds a Markdown cell, starting sections at its headings
+func (f *fileParser) markdownCell(c cell, origin string) {
+	text := f.rewriteImages(string(c.Source), c.Attachments, origin)
+
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence {
+			if match := headingRegex.FindStringSubmatch(line); match != nil {
+				f.startSection(len(match[1]), match[2])
+				continue
+			}
+		}
+		f.lines = append(f.lines, line)
+	}
+	f.lines = append(f.lines, "")
+}
+
+// codeCell adds a code cell as a code block followed by its outputs
+func (f *fileParser) codeCell(c cell, origin string) {
+	source := strings.TrimRight(string(c.Source), "\n")
+	if strings.TrimSpace(source) != "" {
+		marker := fence(source)
+		f.lines = append(f.lines, marker+f.language, source, marker, "")
+	}
+
+	// Consecutive text outputs (print calls, results) share one block
+	var text []string
+	flushText := func() {
+		joined := strings.TrimRight(strings.Join(text, ""), "\n")
+		if strings.TrimSpace(joined) != "" {
+			marker := fence(joined)
+			f.lines = append(f.lines, marker+"text", joined, marker, "")
+		}
+		text = nil
+	}
+
+	for _, out := range c.Outputs {
+		switch out.OutputType {
+		case "stream":
+			text = append(text, ansiRegex.ReplaceAllString(string(out.Text), ""))
+		case "error":
+			traceback := strings.Join(out.Traceback, "\n")
+			if traceback == "" {
+				traceback = out.EName + ": " + out.EValue
+			}
+			text = append(text, ansiRegex.ReplaceAllString(traceback, "")+"\n")
+		case "execute_result", "display_data":
+			if image := f.imageOutput(out, origin); image != "" {
+				flushText()
+				f.lines = append(f.lines, image, "")
+				continue
+			}
+			if markdown := dataText(out.Data["text/markdown"]); markdown != "" {
+				flushText()
+				f.lines = append(f.lines, markdown, "")
+				continue
+			}
+			if plain := dataText(out.Data["text/plain"]); plain != "" {
+				text = append(text, ansiRegex.ReplaceAllString(plain, "")+"\n")
+			}
+		}
+	}
+	flushText()
+}
+
+// fence returns a code fence that cannot be closed by the content: one
+// backtick longer than its longest run of backticks, and at least three
+func fence(content string) string {
+	longest, run := 0, 0
+	for i := 0; i < len(content); i++ {
+		if content[i] != '`' {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// imageOutput saves a PNG or JPEG output and returns its Markdown image, or
+// "" when the output has no image
+func (f *fileParser) imageOutput(out output, origin string) string {
+	for _, mime := range []string{"image/png", "image/jpeg"} {
+		encoded := dataText(out.Data[mime])
+		if encoded == "" {
+			continue
+		}
+		format := strings.TrimPrefix(mime, "image/")
+		name, err := f.saveImage(encoded, format, origin)
+		if err != nil {
+			fmt.Printf("Warning: skipping %s output in %s: %v\n", format, origin, err)
+			return ""
+		}
+		return fmt.Sprintf("![Output](images/%s)", name)
+	}
+	return ""
+}
+
+// rewriteImages saves the images of a Markdown cell, either cell attachments
+// or local files next to the notebook, and points them at the images directory
+func (f *fileParser) rewriteImages(text string, attachments map[string]map[string]multiline, origin string) string {
+	return imageRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := imageRegex.FindStringSubmatch(match)
+		alt, src, rest := parts[1], parts[2], parts[3]
+
+		var name string
+		var err error
+		switch {
+		case strings.HasPrefix(src, "attachment:"):
+			attachment := attachments[strings.TrimPrefix(src, "attachment:")]
+			switch {
+			case attachment["image/png"] != "":
+				name, err = f.saveImage(string(attachment["image/png"]), "png", origin)
+			case attachment["image/jpeg"] != "":
+				name, err = f.saveImage(string(attachment["image/jpeg"]), "jpeg", origin)
+			default:
+				err = fmt.Errorf("no PNG or JPEG data for %s", src)
+			}
+		case strings.Contains(src, "://") || strings.HasPrefix(src, "data:") || filepath.IsAbs(src):
+			return match
+		default:
+			name, err = f.copyImage(src, origin)
+		}
+
+		if err != nil {
+			fmt.Printf("Warning: image %s in %s not copied: %v\n", src, origin, err)
+			return match
+		}
+		return fmt.Sprintf("![%s](images/%s%s)", alt, name, rest)
+	})
+}
+
+// copyImage saves a PNG or JPEG file referenced relative to the notebook
+func (f *fileParser) copyImage(src, origin string) (string, error) {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(src)), ".")
+	if format != "png" && format != "jpg" && format != "jpeg" {
+		return "", fmt.Errorf("unsupported image format: %s", format)
+	}
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(f.path), filepath.FromSlash(src)))
+	if err != nil {
+		return "", err
+	}
+	return f.store(data, format, origin)
+}
+
+// saveImage decodes base64 image data and saves it
+func (f *fileParser) saveImage(encoded, format, origin string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 data: %w", err)
+	}
+	return f.store(data, format, origin)
+}
+
+// store writes an image with pdf.Parser.SaveImageFromData and records where
+// it came from
+func (f *fileParser) store(data []byte, format, origin string) (string, error) {
+	name, err := f.images.SaveImageFromData(data, format, origin)
+	if err != nil {
+		return "", err
+	}
+
+	idx, exists := f.assetIdx[name]
+	if !exists {
+		idx = len(f.assets)
+		f.assetIdx[name] = idx
+		f.assets = append(f.assets, document.ImageAsset{Name: name})
+	}
+	asset := &f.assets[idx]
+	for _, original := range asset.Originals {
+		if original == origin {
+			return name, nil
+		}
+	}
+	asset.Originals = append(asset.Originals, origin)
+	return name, nil
+}
+
+// startSection stores the current section and starts a new one
+func (f *fileParser) startSection(level int, heading string) {
+	f.flush()
+	if level == 1 && f.topTitle == "" {
+		f.topTitle = strings.TrimSpace(heading)
+	}
+	f.sectionID++
+	f.current = &document.Section{
+		ID:      fmt.Sprintf("section-%d", f.sectionID),
+		Level:   level,
+		Heading: strings.TrimSpace(heading),
+		Source:  f.path,
+	}
+}
+
+// flush stores the current section. Content before the first heading goes
+// into a section named after the notebook.
+func (f *fileParser) flush() {
+	markdown := strings.TrimSpace(strings.Join(f.lines, "\n"))
+	f.lines = nil
+
+	if f.current == nil {
+		if markdown == "" {
+			return
+		}
+		f.sectionID++
+		f.current = &document.Section{
+			ID:      fmt.Sprintf("section-%d", f.sectionID),
+			Level:   1,
+			Heading: f.intro,
+			Source:  f.path,
+		}
+	}
+
+	f.current.Markdown = markdown
+	f.sections = append(f.sections, *f.current)
+	f.current = nil
+}
+
+// dataText returns the text of a MIME bundle entry, which may be a string or
+// a list of lines; other JSON values give ""
+func dataText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var m multiline
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return ""
+	}
+	return string(m)
+}
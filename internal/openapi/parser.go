@@ -0,0 +1,571 @@
+package openapi
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/md"
+)
+
+// httpMethods are the operation keys of a path item, in display order
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// maxSchemaDepth limits how deep nested and recursive schemas are expanded
+const maxSchemaDepth = 4
+
+// Parser builds API reference sections from an OpenAPI 3 document
+type Parser struct {
+	sectionID int
+	refs      *resolver
+}
+
+// NewParser creates a new OpenAPI parser
+func NewParser() *Parser {
+	return &Parser{sectionID: 0}
+}
+
+// operation is one method on one path
+type operation struct {
+	method string
+	path   string
+	op     *value
+	item   *value // path item, for parameters shared by all methods
+}
+
+// Parse reads an OpenAPI YAML or JSON file. The API overview comes first,
+// then one section per tag with a subsection per operation.
+func (p *Parser) Parse(path string) (*document.Document, error) {
+	root, err := loadSpec(path)
+	if err != nil {
+		return nil, err
+	}
+	version := root.str("openapi")
+	if version == "" {
+		return nil, fmt.Errorf("%s is not an OpenAPI 3 document (missing openapi field)", path)
+	}
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %s (only 3.x is supported)", version)
+	}
+	p.refs = &resolver{root: root}
+
+	info := root.get("info")
+	doc := &document.Document{
+		Title:    "API Reference",
+		Sections: make([]document.Section, 0),
+	}
+	if title := info.str("title"); title != "" {
+		doc.Title = title
+	}
+
+	add := func(level int, heading string, body []string) {
+		p.sectionID++
+		section := document.Section{
+			ID:       fmt.Sprintf("section-%d", p.sectionID),
+			Level:    level,
+			Heading:  heading,
+			Source:   path,
+			Markdown: strings.TrimSpace(strings.Join(body, "\n")),
+		}
+		md.FinishSection(&section)
+		doc.Sections = append(doc.Sections, section)
+	}
+
+	add(1, doc.Title, p.overview(root))
+
+	// Group operations by their first tag; tags listed at the top level
+	// come first and in their listed order
+	tagOrder, tagDocs := p.tags(root)
+	groups := make(map[string][]operation)
+	paths := root.get("paths")
+	if paths != nil {
+		for _, pathKey := range paths.keys {
+			item := p.refs.resolve(paths.get(pathKey))
+			for _, method := range httpMethods {
+				op := item.get(method)
+				if op == nil {
+					continue
+				}
+				tag := "default"
+				if tags := op.get("tags"); tags != nil && len(tags.items) > 0 {
+					tag = tags.items[0].scalar
+				}
+				if _, seen := groups[tag]; !seen && !slices.Contains(tagOrder, tag) {
+					tagOrder = append(tagOrder, tag)
+				}
+				groups[tag] = append(groups[tag], operation{method: method, path: pathKey, op: op, item: item})
+			}
+		}
+	}
+
+	for _, tag := range tagOrder {
+		ops := groups[tag]
+		if len(ops) == 0 {
+			continue
+		}
+
+		body := []string{}
+		if tagDoc := tagDocs[tag]; tagDoc != nil {
+			body = append(body, tagDoc.str("description"))
+		}
+		body = append(body, "", "| Method | Path | Summary |", "| --- | --- | --- |")
+		for _, o := range ops {
+			body = append(body, fmt.Sprintf("| %s | `%s` | %s |", strings.ToUpper(o.method), o.path, cell(o.op.str("summary"))))
+		}
+		add(1, tag, body)
+
+		for _, o := range ops {
+			add(2, operationHeading(o), p.operation(o))
+		}
+	}
+
+	return doc, nil
+}
+
+// tags returns the top-level tag names in order with their definitions
+func (p *Parser) tags(root *value) ([]string, map[string]*value) {
+	var order []string
+	docs := make(map[string]*value)
+	if tags := root.get("tags"); tags != nil {
+		for _, tag := range tags.items {
+			name := tag.str("name")
+			if name == "" || docs[name] != nil {
+				continue
+			}
+			order = append(order, name)
+			docs[name] = tag
+		}
+	}
+	return order, docs
+}
+
+// overview returns the body of the API overview section
+func (p *Parser) overview(root *value) []string {
+	info := root.get("info")
+	var body []string
+
+	if version := info.str("version"); version != "" {
+		body = append(body, fmt.Sprintf("**Version:** %s", version), "")
+	}
+	if description := info.str("description"); description != "" {
+		body = append(body, description, "")
+	}
+
+	if servers := root.get("servers"); servers != nil && len(servers.items) > 0 {
+		body = append(body, "**Servers**", "")
+		for _, server := range servers.items {
+			line := fmt.Sprintf("- `%s`", server.str("url"))
+			if description := server.str("description"); description != "" {
+				line += " — " + oneLine(description)
+			}
+			body = append(body, line)
+		}
+		body = append(body, "")
+	}
+
+	if schemes := root.get("components").get("securitySchemes"); schemes != nil && len(schemes.keys) > 0 {
+		body = append(body, "**Authentication**", "")
+		for _, name := range schemes.keys {
+			scheme := p.refs.resolve(schemes.get(name))
+			kind := scheme.str("type")
+			if s := scheme.str("scheme"); s != "" {
+				kind += " (" + s + ")"
+			} else if in := scheme.str("in"); in != "" {
+				kind += fmt.Sprintf(" (%s `%s`)", in, scheme.str("name"))
+			}
+			line := fmt.Sprintf("- `%s`: %s", name, kind)
+			if description := scheme.str("description"); description != "" {
+				line += " — " + oneLine(description)
+			}
+			body = append(body, line)
+		}
+	}
+
+	return body
+}
+
+// operationHeading returns the section heading of an operation
+func operationHeading(o operation) string {
+	if summary := oneLine(o.op.str("summary")); summary != "" {
+		return summary
+	}
+	return strings.ToUpper(o.method) + " " + o.path
+}
+
+// operation returns the body of an operation section
+func (p *Parser) operation(o operation) []string {
+	op := o.op
+	body := []string{"```http", strings.ToUpper(o.method) + " " + o.path, "```", ""}
+
+	if op.isTrue("deprecated") {
+		body = append(body, ":::warning Deprecated", "This operation is deprecated.", ":::", "")
+	}
+	if description := op.str("description"); description != "" {
+		body = append(body, description, "")
+	}
+	if id := op.str("operationId"); id != "" {
+		body = append(body, fmt.Sprintf("**Operation ID:** `%s`", id), "")
+	}
+
+	body = append(body, p.parameters(o)...)
+
+	if requestBody := p.refs.resolve(op.get("requestBody")); requestBody != nil {
+		title := "**Request body**"
+		if requestBody.isTrue("required") {
+			title += " (required)"
+		}
+		body = append(body, title, "")
+		if description := requestBody.str("description"); description != "" {
+			body = append(body, description, "")
+		}
+		body = append(body, p.content(requestBody.get("content"))...)
+	}
+
+	if responses := op.get("responses"); responses != nil && len(responses.keys) > 0 {
+		body = append(body, "**Responses**", "")
+		for _, status := range responses.keys {
+			response := p.refs.resolve(responses.get(status))
+			body = append(body, fmt.Sprintf("`%s` %s", status, oneLine(response.str("description"))), "")
+			body = append(body, p.content(response.get("content"))...)
+		}
+	}
+
+	if security := op.get("security"); security != nil {
+		var schemes []string
+		for _, requirement := range security.items {
+			schemes = append(schemes, requirement.keys...)
+		}
+		if len(schemes) > 0 {
+			body = append(body, fmt.Sprintf("**Authentication:** `%s`", strings.Join(schemes, "`, `")), "")
+		} else {
+			body = append(body, "**Authentication:** none", "")
+		}
+	}
+
+	return body
+}
+
+// parameters returns the parameter table of an operation, including the
+// parameters shared by its path
+func (p *Parser) parameters(o operation) []string {
+	type param struct{ name, in string }
+	var params []*value
+	index := make(map[param]int)
+
+	for _, source := range []*value{o.item.get("parameters"), o.op.get("parameters")} {
+		if source == nil {
+			continue
+		}
+		for _, raw := range source.items {
+			parameter := p.refs.resolve(raw)
+			if parameter == nil {
+				continue
+			}
+			key := param{parameter.str("name"), parameter.str("in")}
+			// Operation parameters override path parameters
+			if i, exists := index[key]; exists {
+				params[i] = parameter
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, parameter)
+		}
+	}
+	if len(params) == 0 {
+		return nil
+	}
+
+	lines := []string{"**Parameters**", "", "| Name | In | Type | Required | Description |", "| --- | --- | --- | --- | --- |"}
+	for _, parameter := range params {
+		required := ""
+		if parameter.isTrue("required") {
+			required = "yes"
+		}
+		description := parameter.str("description")
+		if example := parameter.get("example"); example != nil {
+			description = strings.TrimSpace(description + " Example: `" + oneLine(toJSON(example)) + "`")
+		}
+		lines = append(lines, fmt.Sprintf("| `%s` | %s | %s | %s | %s |",
+			parameter.str("name"), parameter.str("in"), cell(p.schemaType(parameter.get("schema"), 0)), required, cell(description)))
+	}
+	return append(lines, "")
+}
+
+// content returns the schema and example of each media type
+func (p *Parser) content(content *value) []string {
+	if content == nil {
+		return nil
+	}
+
+	var lines []string
+	for _, mediaType := range content.keys {
+		media := content.get(mediaType)
+		schema := media.get("schema")
+
+		lines = append(lines, fmt.Sprintf("Content type `%s`, schema %s", mediaType, p.schemaType(schema, 0)), "")
+		if fields := p.schemaFields(schema); len(fields) > 0 {
+			lines = append(lines, "| Field | Type | Required | Description |", "| --- | --- | --- | --- |")
+			lines = append(lines, fields...)
+			lines = append(lines, "")
+		}
+
+		if example := p.example(media); example != "" {
+			lines = append(lines, "Example:", "", "```"+exampleLanguage(mediaType), example, "```", "")
+		}
+	}
+	return lines
+}
+
+// schemaType describes a schema in a few words, e.g. "array of User"
+func (p *Parser) schemaType(schema *value, depth int) string {
+	if schema == nil {
+		return ""
+	}
+	if name := refName(schema); name != "" {
+		return name
+	}
+	if depth > maxSchemaDepth {
+		return "object"
+	}
+
+	for _, combinator := range []string{"oneOf", "anyOf", "allOf"} {
+		if list := schema.get(combinator); list != nil {
+			var names []string
+			for _, item := range list.items {
+				names = append(names, p.schemaType(item, depth+1))
+			}
+			word := map[string]string{"oneOf": "one of", "anyOf": "any of", "allOf": "all of"}[combinator]
+			return word + " " + strings.Join(names, ", ")
+		}
+	}
+
+	kind := schema.str("type")
+	if types := schema.get("type"); types != nil && types.isList {
+		// OpenAPI 3.1 allows a list of types
+		var names []string
+		for _, t := range types.items {
+			names = append(names, t.scalar)
+		}
+		kind = strings.Join(names, " | ")
+	}
+
+	switch {
+	case kind == "array":
+		return "array of " + p.schemaType(schema.get("items"), depth+1)
+	case kind == "" && schema.get("properties") != nil:
+		kind = "object"
+	}
+	if format := schema.str("format"); format != "" {
+		kind += " (" + format + ")"
+	}
+	if enum := schema.get("enum"); enum != nil {
+		var values []string
+		for _, item := range enum.items {
+			values = append(values, item.scalar)
+		}
+		kind += ": " + strings.Join(values, ", ")
+	}
+	if kind == "" {
+		kind = "any"
+	}
+	return kind
+}
+
+// schemaFields returns table rows for the properties of an object schema.
+// Nested objects are listed with dotted names and arrays of objects with "[]".
+func (p *Parser) schemaFields(schema *value) []string {
+	var rows []string
+	seen := make(map[*value]bool)
+
+	var walk func(schema *value, prefix string, depth int)
+	walk = func(schema *value, prefix string, depth int) {
+		schema = p.refs.resolve(schema)
+		if schema == nil || depth > maxSchemaDepth || seen[schema] {
+			return
+		}
+		seen[schema] = true
+		defer delete(seen, schema)
+
+		if schema.str("type") == "array" {
+			walk(schema.get("items"), prefix+"[]", depth+1)
+			return
+		}
+
+		// allOf combines the properties of all its schemas
+		if all := schema.get("allOf"); all != nil {
+			for _, part := range all.items {
+				walk(part, prefix, depth)
+			}
+		}
+
+		properties := schema.get("properties")
+		if properties == nil {
+			return
+		}
+		required := make(map[string]bool)
+		if list := schema.get("required"); list != nil {
+			for _, item := range list.items {
+				required[item.scalar] = true
+			}
+		}
+
+		for _, name := range properties.keys {
+			property := properties.get(name)
+			resolved := p.refs.resolve(property)
+			field := name
+			if prefix != "" {
+				field = prefix + "." + name
+			}
+
+			req := ""
+			if required[name] {
+				req = "yes"
+			}
+			description := resolved.str("description")
+			if resolved.isTrue("readOnly") {
+				description = strings.TrimSpace("Read-only. " + description)
+			}
+			rows = append(rows, fmt.Sprintf("| `%s` | %s | %s | %s |", field, cell(p.schemaType(property, 0)), req, cell(description)))
+
+			walk(property, field, depth+1)
+		}
+	}
+	walk(schema, "", 0)
+
+	return rows
+}
+
+// example returns the example of a media type as text: the media example,
+// the first named example, the schema example or one built from the schema
+func (p *Parser) example(media *value) string {
+	if example := media.get("example"); example != nil {
+		return exampleText(example)
+	}
+	if examples := media.get("examples"); examples != nil && len(examples.keys) > 0 {
+		first := p.refs.resolve(examples.get(examples.keys[0]))
+		if v := first.get("value"); v != nil {
+			return exampleText(v)
+		}
+	}
+	if schema := media.get("schema"); schema != nil {
+		if built := p.buildExample(schema, 0, make(map[*value]bool)); built != nil {
+			return toJSON(built)
+		}
+	}
+	return ""
+}
+
+// exampleText returns an example value; strings are shown as they are
+func exampleText(v *value) string {
+	if !v.isList && v.fields == nil {
+		return v.scalar
+	}
+	return toJSON(v)
+}
+
+// buildExample builds an example value from a schema's examples, defaults
+// and types
+func (p *Parser) buildExample(schema *value, depth int, seen map[*value]bool) *value {
+	schema = p.refs.resolve(schema)
+	if schema == nil || depth > maxSchemaDepth || seen[schema] {
+		return nil
+	}
+	seen[schema] = true
+	defer delete(seen, schema)
+
+	for _, key := range []string{"example", "default"} {
+		if v := schema.get(key); v != nil {
+			return v
+		}
+	}
+	if examples := schema.get("examples"); examples != nil && len(examples.items) > 0 {
+		return examples.items[0]
+	}
+	if enum := schema.get("enum"); enum != nil && len(enum.items) > 0 {
+		return enum.items[0]
+	}
+	for _, combinator := range []string{"oneOf", "anyOf"} {
+		if list := schema.get(combinator); list != nil && len(list.items) > 0 {
+			return p.buildExample(list.items[0], depth+1, seen)
+		}
+	}
+
+	kind := schema.str("type")
+	if kind == "" && (schema.get("properties") != nil || schema.get("allOf") != nil) {
+		kind = "object"
+	}
+
+	switch kind {
+	case "object":
+		obj := &value{fields: make(map[string]*value)}
+		var parts []*value
+		if all := schema.get("allOf"); all != nil {
+			parts = append(parts, all.items...)
+		}
+		parts = append(parts, schema)
+		for _, part := range parts {
+			part = p.refs.resolve(part)
+			properties := part.get("properties")
+			if properties == nil {
+				continue
+			}
+			for _, name := range properties.keys {
+				if v := p.buildExample(properties.get(name), depth+1, seen); v != nil {
+					if _, exists := obj.fields[name]; !exists {
+						obj.keys = append(obj.keys, name)
+					}
+					obj.fields[name] = v
+				}
+			}
+		}
+		return obj
+	case "array":
+		list := &value{isList: true}
+		if item := p.buildExample(schema.get("items"), depth+1, seen); item != nil {
+			list.items = append(list.items, item)
+		}
+		return list
+	case "integer", "number":
+		return &value{scalar: "0", tag: "!!int"}
+	case "boolean":
+		return &value{scalar: "true", tag: "!!bool"}
+	case "string":
+		text := "string"
+		switch schema.str("format") {
+		case "date-time":
+			text = "2024-01-01T00:00:00Z"
+		case "date":
+			text = "2024-01-01"
+		case "email":
+			text = "user@example.com"
+		case "uuid":
+			text = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+		case "uri", "url":
+			text = "https://example.com"
+		}
+		return &value{scalar: text, tag: "!!str"}
+	}
+	return nil
+}
+
+// exampleLanguage returns the code block language for a media type
+func exampleLanguage(mediaType string) string {
+	switch {
+	case strings.Contains(mediaType, "json"):
+		return "json"
+	case strings.Contains(mediaType, "yaml"):
+		return "yaml"
+	}
+	return ""
+}
+
+// cell makes text safe for a pipe table cell
+func cell(text string) string {
+	return strings.ReplaceAll(oneLine(text), "|", `\|`)
+}
+
+// oneLine collapses whitespace, including line breaks, into single spaces
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,206 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// value is a node of an OpenAPI document. Objects keep their key order so
+// paths, properties and responses appear as they do in the spec.
+type value struct {
+	keys   []string          // object keys in document order
+	fields map[string]*value // object members
+	items  []*value          // array elements
+	scalar string            // scalar text
+	tag    string            // YAML tag of a scalar (!!str, !!int, !!bool, ...)
+	isList bool
+}
+
+// loadSpec reads a YAML or JSON OpenAPI document. JSON is read by the YAML
+// decoder, which keeps object key order.
+func loadSpec(path string) (*value, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, fmt.Errorf("spec is empty")
+	}
+
+	return convert(root.Content[0]), nil
+}
+
+// convert turns a YAML node into a value
+func convert(n *yaml.Node) *value {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return convert(n.Alias)
+	case yaml.MappingNode:
+		v := &value{fields: make(map[string]*value)}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			if _, exists := v.fields[key]; !exists {
+				v.keys = append(v.keys, key)
+			}
+			v.fields[key] = convert(n.Content[i+1])
+		}
+		return v
+	case yaml.SequenceNode:
+		v := &value{isList: true}
+		for _, item := range n.Content {
+			v.items = append(v.items, convert(item))
+		}
+		return v
+	default:
+		return &value{scalar: n.Value, tag: n.ShortTag()}
+	}
+}
+
+// get returns an object member, or nil
+func (v *value) get(key string) *value {
+	if v == nil || v.fields == nil {
+		return nil
+	}
+	return v.fields[key]
+}
+
+// str returns the scalar text of an object member, or ""
+func (v *value) str(key string) string {
+	if m := v.get(key); m != nil {
+		return m.scalar
+	}
+	return ""
+}
+
+// isTrue reports whether an object member is the boolean true
+func (v *value) isTrue(key string) bool {
+	return v.str(key) == "true"
+}
+
+// resolver follows local $ref pointers ("#/components/schemas/User")
+type resolver struct {
+	root *value
+}
+
+// resolve returns the value a $ref points at, following chains of refs.
+// Values without a $ref are returned unchanged; unresolvable refs return nil.
+func (r *resolver) resolve(v *value) *value {
+	for i := 0; v != nil && i < 32; i++ {
+		ref := v.str("$ref")
+		if ref == "" {
+			return v
+		}
+		v = r.lookup(ref)
+	}
+	return v
+}
+
+// lookup resolves a JSON pointer within the document
+func (r *resolver) lookup(ref string) *value {
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		// References to other files are not followed
+		return nil
+	}
+
+	v := r.root
+	for _, token := range strings.Split(pointer, "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		if v.isList {
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(v.items) {
+				return nil
+			}
+			v = v.items[idx]
+			continue
+		}
+		if v = v.get(token); v == nil {
+			return nil
+		}
+	}
+	return v
+}
+
+// refName returns the last segment of a value's $ref, e.g. "User"
+func refName(v *value) string {
+	ref := v.str("$ref")
+	if ref == "" {
+		return ""
+	}
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// toJSON writes a value as indented JSON, keeping key order
+func toJSON(v *value) string {
+	var b bytes.Buffer
+	writeJSON(&b, v, "")
+	return b.String()
+}
+
+// writeJSON writes one value at the given indentation
+func writeJSON(b *bytes.Buffer, v *value, indent string) {
+	switch {
+	case v == nil:
+		b.WriteString("null")
+	case v.isList:
+		if len(v.items) == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteString("[\n")
+		for i, item := range v.items {
+			b.WriteString(indent + "  ")
+			writeJSON(b, item, indent+"  ")
+			if i < len(v.items)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "]")
+	case v.fields != nil:
+		if len(v.keys) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		b.WriteString("{\n")
+		for i, key := range v.keys {
+			name, _ := json.Marshal(key)
+			b.WriteString(indent + "  " + string(name) + ": ")
+			writeJSON(b, v.fields[key], indent+"  ")
+			if i < len(v.keys)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "}")
+	default:
+		b.WriteString(scalarJSON(v))
+	}
+}
+
+// scalarJSON returns a scalar as a JSON literal
+func scalarJSON(v *value) string {
+	switch v.tag {
+	case "!!int", "!!float":
+		if _, err := strconv.ParseFloat(v.scalar, 64); err == nil {
+			return v.scalar
+		}
+	case "!!bool":
+		return strings.ToLower(v.scalar)
+	case "!!null":
+		return "null"
+	}
+	text, _ := json.Marshal(v.scalar)
+	return string(text)
+}
@@ -11,29 +11,17 @@ import (
 	"regexp"
 	"strings"
 
+	"docTrainerGO/internal/document"
 	"github.com/ledongthuc/pdf"
 )
 
-// Section represents a documentation section with heading, content, and images
-type Section struct {
-	ID      string   // Unique identifier for the section
-	Level   int      // Heading level (1-6)
-	Heading string   // Section heading text
-	Content string   // Section text content
-	Images  []string // Paths to extracted images
-}
-
-// Document represents the parsed PDF document
-type Document struct {
-	Title    string
-	Sections []Section
-}
-
 // Parser handles PDF parsing and image extraction
 type Parser struct {
-	outputDir string
-	imageDir  string
-	imageIdx  int
+	outputDir  string
+	imageDir   string
+	imageIdx   int
+	images     *document.ImageStore
+	imageFiles []string // images extracted from this PDF, in page order
 }
 
 // NewParser creates a new PDF parser
@@ -42,11 +30,18 @@ func NewParser(outputDir string) *Parser {
 		outputDir: outputDir,
 		imageDir:  filepath.Join(outputDir, "images"),
 		imageIdx:  0,
+		images:    document.NewImageStore(filepath.Join(outputDir, "images")),
 	}
 }
 
+// SetImageFiles sets the images extracted from the PDF (e.g. by pdfimages),
+// which are stored and distributed across the sections
+func (p *Parser) SetImageFiles(files []string) {
+	p.imageFiles = files
+}
+
 // Parse extracts text and images from a PDF file
-func (p *Parser) Parse(pdfPath string) (*Document, error) {
+func (p *Parser) Parse(pdfPath string) (*document.Document, error) {
 	// Ensure image directory exists
 	if err := os.MkdirAll(p.imageDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create image directory: %w", err)
@@ -59,9 +54,9 @@ func (p *Parser) Parse(pdfPath string) (*Document, error) {
 	}
 	defer f.Close()
 
-	doc := &Document{
+	doc := &document.Document{
 		Title:    extractTitle(pdfPath),
-		Sections: make([]Section, 0),
+		Sections: make([]document.Section, 0),
 	}
 
 	// Extract text from all pages
@@ -91,6 +86,8 @@ func (p *Parser) Parse(pdfPath string) (*Document, error) {
 
 	// Parse text into sections
 	doc.Sections = p.parseTextIntoSections(allText.String())
+	p.attachImages(doc.Sections, pdfPath)
+	doc.Images = p.images.Assets()
 
 	return doc, nil
 }
@@ -129,11 +126,11 @@ func (p *Parser) extractImagesFromPage(page pdf.Page, pageNum int) error {
 }
 
 // parseTextIntoSections converts plain text into structured sections
-func (p *Parser) parseTextIntoSections(text string) []Section {
-	sections := make([]Section, 0)
+func (p *Parser) parseTextIntoSections(text string) []document.Section {
+	sections := make([]document.Section, 0)
 	lines := strings.Split(text, "\n")
 
-	var currentSection *Section
+	var currentSection *document.Section
 	sectionID := 0
 
 	// Regular expressions for detecting headings
@@ -168,12 +165,12 @@ func (p *Parser) parseTextIntoSections(text string) []Section {
 
 			// Create new section
 			sectionID++
-			currentSection = &Section{
+			currentSection = &document.Section{
 				ID:      fmt.Sprintf("section-%d", sectionID),
 				Level:   level,
 				Heading: line,
 				Content: "",
-				Images:  make([]string, 0),
+				Images:  make([]document.Image, 0),
 			}
 		} else if currentSection != nil {
 			// Add content to current section
@@ -184,12 +181,12 @@ func (p *Parser) parseTextIntoSections(text string) []Section {
 		} else {
 			// Create initial section for content before first heading
 			sectionID++
-			currentSection = &Section{
+			currentSection = &document.Section{
 				ID:      fmt.Sprintf("section-%d", sectionID),
 				Level:   1,
 				Heading: "Introduction",
 				Content: line,
-				Images:  make([]string, 0),
+				Images:  make([]document.Image, 0),
 			}
 		}
 	}
@@ -199,28 +196,46 @@ func (p *Parser) parseTextIntoSections(text string) []Section {
 		sections = append(sections, *currentSection)
 	}
 
-	// Associate images with sections (simplified approach)
-	imageFiles, _ := filepath.Glob(filepath.Join(p.imageDir, "*.png"))
-	imageFiles2, _ := filepath.Glob(filepath.Join(p.imageDir, "*.jpg"))
-	allImages := append(imageFiles, imageFiles2...)
+	return sections
+}
 
-	// Distribute images across sections
-	if len(sections) > 0 && len(allImages) > 0 {
-		imagesPerSection := len(allImages) / len(sections)
-		if imagesPerSection == 0 {
-			imagesPerSection = 1
+// attachImages stores the extracted images and distributes them across the
+// sections in order (simplified approach: text and images are not aligned)
+func (p *Parser) attachImages(sections []document.Section, pdfPath string) {
+	var names []string
+	for _, file := range p.imageFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Printf("  Warning: failed to read extracted image %s: %v\n", file, err)
+			continue
 		}
-
-		imgIdx := 0
-		for i := range sections {
-			for j := 0; j < imagesPerSection && imgIdx < len(allImages); j++ {
-				sections[i].Images = append(sections[i].Images, filepath.Base(allImages[imgIdx]))
-				imgIdx++
-			}
+		name, err := p.images.Save(data, filepath.Ext(file), pdfPath+"#"+filepath.Base(file))
+		if err != nil {
+			fmt.Printf("  Warning: failed to store image %s: %v\n", file, err)
+			continue
 		}
+		names = append(names, name)
+	}
+	if len(sections) == 0 || len(names) == 0 {
+		return
 	}
 
-	return sections
+	imagesPerSection := len(names) / len(sections)
+	if imagesPerSection == 0 {
+		imagesPerSection = 1
+	}
+
+	imgIdx := 0
+	for i := range sections {
+		for j := 0; j < imagesPerSection && imgIdx < len(names); j++ {
+			sections[i].Images = append(sections[i].Images, document.Image{
+				Src:      names[imgIdx],
+				Alt:      sections[i].Heading,
+				Position: -1,
+			})
+			imgIdx++
+		}
+	}
 }
 
 // extractTitle extracts document title from PDF filename
@@ -232,38 +247,29 @@ func extractTitle(pdfPath string) string {
 	return title
 }
 
-// SaveImageFromData saves image data to a file
-func (p *Parser) SaveImageFromData(data []byte, format string) (string, error) {
-	p.imageIdx++
-	filename := fmt.Sprintf("image_%d.%s", p.imageIdx, format)
-	filepath := filepath.Join(p.imageDir, filename)
-
-	file, err := os.Create(filepath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create image file: %w", err)
-	}
-	defer file.Close()
-
-	// Decode and re-encode image
+// SaveImageFromData decodes image data, re-encodes it and stores it in the
+// images directory. Nothing is written unless the data decodes. origin
+// records where the image came from.
+func (p *Parser) SaveImageFromData(data []byte, format, origin string) (string, error) {
 	img, err := decodeImage(data, format)
 	if err != nil {
 		return "", fmt.Errorf("failed to decode image: %w", err)
 	}
 
+	var buf bytes.Buffer
 	switch format {
 	case "png":
-		err = png.Encode(file, img)
+		err = png.Encode(&buf, img)
 	case "jpg", "jpeg":
-		err = jpeg.Encode(file, img, &jpeg.Options{Quality: 90})
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90})
 	default:
 		return "", fmt.Errorf("unsupported image format: %s", format)
 	}
-
 	if err != nil {
 		return "", fmt.Errorf("failed to encode image: %w", err)
 	}
 
-	return filename, nil
+	return p.images.Save(buf.Bytes(), "."+format, origin)
 }
 
 // decodeImage decodes image data based on format
@@ -0,0 +1,76 @@
+package processor
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"docTrainerGO/internal/assets"
+	"docTrainerGO/internal/epub"
+	"docTrainerGO/internal/generator"
+)
+
+// ExportHTML writes the built documentation as one self-contained HTML file.
+// Multi-version and multi-language builds export their default build.
+func (p *Processor) ExportHTML(path string) error {
+	fmt.Println("→ Exporting single-file HTML...")
+	buildDir := p.defaultBuildDir()
+
+	theme, err := generator.LoadTheme(p.config.Theme, buildDir)
+	if err != nil {
+		return fmt.Errorf("failed to load theme: %w", err)
+	}
+
+	exporter := generator.NewOfflineExporter(assets.New(p.config), "templates/page.html", buildDir)
+	exporter.SetTheme(theme)
+	if err := exporter.Export(path); err != nil {
+		return fmt.Errorf("failed to export HTML: %w", err)
+	}
+	return nil
+}
+
+// ExportEPUB writes the built documentation as an EPUB 3 book, with one
+// chapter per top-level section
+func (p *Processor) ExportEPUB(path string) error {
+	fmt.Println("→ Exporting EPUB...")
+	buildDir := p.defaultBuildDir()
+
+	doc, err := generator.LoadDocument(buildDir)
+	if err != nil {
+		return err
+	}
+	stylesheet, err := fs.ReadFile(assets.New(p.config), "static/epub.css")
+	if err != nil {
+		return fmt.Errorf("failed to read epub.css: %w", err)
+	}
+
+	writer := epub.NewWriter(filepath.Join(buildDir, "images"))
+	writer.SetAuthor(p.config.Output.Author)
+	writer.SetStylesheet(stylesheet)
+	if err := writer.Write(doc, path); err != nil {
+		return fmt.Errorf("failed to export EPUB: %w", err)
+	}
+	return nil
+}
+
+// defaultBuildDir returns the output directory of the default version or
+// canonical locale, or the output directory itself for single builds
+func (p *Processor) defaultBuildDir() string {
+	outputDir := p.config.Output.Directory
+
+	if len(p.config.Versions) > 0 {
+		name := p.config.Versions[0].Name
+		for _, version := range p.config.Versions {
+			if version.Default {
+				name = version.Name
+			}
+		}
+		return filepath.Join(outputDir, name)
+	}
+
+	if len(p.config.Markdown.Locales) > 0 {
+		return filepath.Join(outputDir, canonicalFirst(p.config.Markdown.Locales)[0].Code)
+	}
+
+	return outputDir
+}
@@ -0,0 +1,133 @@
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+)
+
+// LocaleManifest lists the languages built into the output directory
+type LocaleManifest struct {
+	Default string        `json:"default"`
+	Locales []LocaleEntry `json:"locales"`
+}
+
+// LocaleEntry describes one built language
+type LocaleEntry struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// processLocales builds every configured locale into <output>/<code>/ and
+// links translated sections back to the canonical locale's section IDs
+func (p *Processor) processLocales() error {
+	outputDir := p.config.Output.Directory
+	locales := canonicalFirst(p.config.Markdown.Locales)
+	manifest := LocaleManifest{
+		Default: locales[0].Code,
+		Locales: make([]LocaleEntry, 0, len(locales)),
+	}
+
+	// Section keys of the canonical locale -> canonical section ID
+	canonicalIDs := make(map[string]string)
+
+	for i, locale := range locales {
+		if err := validateLocaleCode(locale.Code); err != nil {
+			return err
+		}
+		fmt.Printf("\n=== Locale %s ===\n", locale.Code)
+
+		// Build the locale with its own copy of the configuration
+		localeCfg := *p.config
+		localeCfg.Markdown.Locales = nil
+		localeCfg.InputType = "markdown"
+		localeCfg.Markdown.Directory = locale.Directory
+		localeCfg.Markdown.AutoDiscover = true
+		localeCfg.Output.Directory = filepath.Join(outputDir, locale.Code)
+		localeCfg.Output.BaseURL = joinURL(p.config.Output.BaseURL, locale.Code)
+
+		proc := New(&localeCfg)
+		doc, err := proc.parse()
+		if err != nil {
+			return fmt.Errorf("locale %s: %w", locale.Code, err)
+		}
+		doc.Language = locale.Code
+
+		// Link sections to the canonical locale by file and position
+		keys := sectionKeys(doc, locale.Directory)
+		linked := 0
+		for j := range doc.Sections {
+			if i == 0 {
+				canonicalIDs[keys[j]] = doc.Sections[j].ID
+			}
+			if id, ok := canonicalIDs[keys[j]]; ok {
+				doc.Sections[j].CanonicalID = id
+				linked++
+			}
+		}
+		if i > 0 {
+			fmt.Printf("  Linked %d of %d sections to %s\n", linked, len(doc.Sections), locales[0].Code)
+		}
+
+		if err := proc.generate(doc); err != nil {
+			return fmt.Errorf("locale %s: %w", locale.Code, err)
+		}
+
+		name := locale.Name
+		if name == "" {
+			name = locale.Code
+		}
+		manifest.Locales = append(manifest.Locales, LocaleEntry{
+			Code: locale.Code,
+			Name: name,
+			Path: "/" + locale.Code + "/",
+		})
+	}
+
+	manifestPath := filepath.Join(outputDir, "locales.json")
+	if err := writeManifest(manifestPath, manifest); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nGenerated locale manifest: %s (%d locales)\n", manifestPath, len(manifest.Locales))
+	return nil
+}
+
+// canonicalFirst returns the locales with the default (canonical) one first
+func canonicalFirst(locales []config.Locale) []config.Locale {
+	ordered := make([]config.Locale, 0, len(locales))
+	for _, locale := range locales {
+		if locale.Default {
+			ordered = append(ordered, locale)
+		}
+	}
+	if len(ordered) == 0 {
+		ordered = append(ordered, locales[0])
+	}
+	for _, locale := range locales {
+		if locale.Code != ordered[0].Code {
+			ordered = append(ordered, locale)
+		}
+	}
+	return ordered
+}
+
+// sectionKeys identifies each section by its source file relative to the
+// locale directory and its position within that file
+func sectionKeys(doc *document.Document, dir string) []string {
+	keys := make([]string, len(doc.Sections))
+	positions := make(map[string]int)
+	for i, section := range doc.Sections {
+		rel, err := filepath.Rel(dir, section.Source)
+		if err != nil {
+			rel = section.Source
+		}
+		rel = filepath.ToSlash(rel)
+		keys[i] = fmt.Sprintf("%s#%d", rel, positions[rel])
+		positions[rel]++
+	}
+	return keys
+}
@@ -2,16 +2,14 @@ package processor
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 
+	"docTrainerGO/internal/assets"
 	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
 	"docTrainerGO/internal/generator"
-	"docTrainerGO/internal/md"
-	"docTrainerGO/internal/pdf"
 	"docTrainerGO/internal/search"
+	"docTrainerGO/internal/source"
 )
 
 // Processor handles document processing
@@ -26,150 +24,103 @@ func New(cfg *config.Config) *Processor {
 
 // Process processes documents based on configuration
 func (p *Processor) Process() error {
-	outputDir := p.config.Output.Directory
-
-	var doc *pdf.Document
-	var err error
-
-	switch p.config.InputType {
-	case "markdown":
-		fmt.Println("Processing Markdown files...")
-		doc, err = p.processMarkdown(outputDir)
-	case "pdf":
-		fmt.Println("Processing PDF file...")
-		doc, err = p.processPDF(outputDir)
-	default:
-		return fmt.Errorf("invalid input_type: %s (must be 'pdf' or 'markdown')", p.config.InputType)
-	}
-
-	if err != nil {
-		return err
-	}
-
-	fmt.Printf("  Found %d sections\n", len(doc.Sections))
-
-	// Generate structured data
-	fmt.Println("→ Generating structured data...")
-	dataGen := generator.NewDataGenerator(outputDir)
-	if err := dataGen.Generate(doc); err != nil {
-		return fmt.Errorf("failed to generate data files: %w", err)
+	if len(p.config.Sources) > 0 && (len(p.config.Versions) > 0 || len(p.config.Markdown.Locales) > 0) {
+		return fmt.Errorf("sources cannot be combined with versions or markdown.locales")
 	}
 
-	// Generate HTML
-	fmt.Println("→ Generating HTML pages...")
-	gen := generator.NewGenerator("templates/page.html", outputDir)
-	if err := gen.Generate(doc); err != nil {
-		return fmt.Errorf("failed to generate HTML: %w", err)
-	}
-
-	// Generate search index
-	fmt.Println("→ Creating search index...")
-	indexGen := search.NewIndexGenerator(outputDir)
-	if err := indexGen.Generate(doc); err != nil {
-		return fmt.Errorf("failed to generate search index: %w", err)
+	// Multi-version mode builds each version into its own subdirectory
+	if len(p.config.Versions) > 0 {
+		if len(p.config.Markdown.Locales) > 0 {
+			return fmt.Errorf("versions and markdown.locales cannot be combined")
+		}
+		return p.processVersions()
 	}
 
-	return nil
-}
-
-// processMarkdown processes markdown files
-func (p *Processor) processMarkdown(outputDir string) (*pdf.Document, error) {
-	parser := md.NewParser(outputDir)
-
-	var doc *pdf.Document
-	var err error
-
-	if p.config.Markdown.AutoDiscover {
-		fmt.Printf("→ Auto-discovering files in: %s\n", p.config.Markdown.Directory)
-		doc, err = parser.ParseDirectory(p.config.Markdown.Directory)
-	} else {
-		fmt.Printf("→ Processing %d specified files\n", len(p.config.Markdown.Files))
-		doc, err = parser.ParseFiles(p.config.Markdown.Files)
+	// Multi-language mode builds each locale into its own subdirectory
+	if len(p.config.Markdown.Locales) > 0 {
+		return p.processLocales()
 	}
 
+	doc, err := p.parse()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse markdown: %w", err)
-	}
-
-	// Set title from config
-	if p.config.Output.Title != "" {
-		doc.Title = p.config.Output.Title
+		return err
 	}
 
-	return doc, nil
+	return p.generate(doc)
 }
 
-// processPDF processes a PDF file
-func (p *Processor) processPDF(outputDir string) (*pdf.Document, error) {
-	pdfPath := p.config.PDF.Path
-	if pdfPath == "" {
-		return nil, fmt.Errorf("PDF path not specified in config")
-	}
-
-	fmt.Println("Processing PDF:", pdfPath)
+// parse reads the configured input into a document
+func (p *Processor) parse() (*document.Document, error) {
+	outputDir := p.config.Output.Directory
 
-	// Check if PDF exists
-	if _, err := os.Stat(pdfPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("PDF file not found: %s", pdfPath)
+	// Mixed-source builds merge several inputs into one document
+	if len(p.config.Sources) > 0 {
+		doc, err := p.parseSources()
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("\n  Found %d sections in %d sources\n", len(doc.Sections), len(p.config.Sources))
+		return doc, nil
 	}
 
-	// Extract images if enabled
-	if p.config.PDF.ExtractImages {
-		fmt.Println("→ Extracting images from PDF...")
-		if err := extractImagesWithPdfimages(pdfPath, outputDir); err != nil {
-			fmt.Printf("  Warning: Image extraction failed: %v\n", err)
-			fmt.Println("  Continuing without images...")
-		}
+	src, ok := source.New(p.config.InputType)
+	if !ok {
+		return nil, fmt.Errorf("invalid input_type: %s (must be one of: %s)", p.config.InputType, strings.Join(source.Types(), ", "))
 	}
 
-	// Parse PDF
-	fmt.Println("→ Parsing PDF and extracting content...")
-	parser := pdf.NewParser(outputDir)
-	doc, err := parser.Parse(pdfPath)
+	doc, err := src.Load(p.config, outputDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse PDF: %w", err)
+		return nil, err
 	}
 
+	fmt.Printf("  Found %d sections\n", len(doc.Sections))
 	return doc, nil
 }
 
-// ProcessPDFDirect processes a PDF file directly (for CLI usage)
-func ProcessPDFDirect(pdfPath, outputDir string) error {
-	// Check if PDF exists
-	if _, err := os.Stat(pdfPath); os.IsNotExist(err) {
-		return fmt.Errorf("PDF file not found: %s", pdfPath)
-	}
-
-	// Extract images using pdfimages
-	fmt.Println("→ Extracting images from PDF...")
-	if err := extractImagesWithPdfimages(pdfPath, outputDir); err != nil {
-		fmt.Printf("  Warning: Image extraction failed: %v\n", err)
-		fmt.Println("  Continuing without images...")
-	}
-
-	// Parse PDF
-	fmt.Println("→ Parsing PDF and extracting content...")
-	parser := pdf.NewParser(outputDir)
-	doc, err := parser.Parse(pdfPath)
-	if err != nil {
-		return fmt.Errorf("failed to parse PDF: %w", err)
-	}
-	fmt.Printf("  Found %d sections\n", len(doc.Sections))
+// generate writes the data files, HTML and search index for a document
+func (p *Processor) generate(doc *document.Document) error {
+	outputDir := p.config.Output.Directory
 
-	// Generate structured data files
+	// Generate structured data
 	fmt.Println("→ Generating structured data...")
 	dataGen := generator.NewDataGenerator(outputDir)
 	if err := dataGen.Generate(doc); err != nil {
 		return fmt.Errorf("failed to generate data files: %w", err)
 	}
 
+	// Prepare the theme's branding options
+	theme, err := generator.LoadTheme(p.config.Theme, outputDir)
+	if err != nil {
+		return fmt.Errorf("failed to load theme: %w", err)
+	}
+
 	// Generate HTML
 	fmt.Println("→ Generating HTML pages...")
-	gen := generator.NewGenerator("templates/page.html", outputDir)
+	files := assets.New(p.config)
+	gen := generator.NewGenerator(files, "templates/page.html", outputDir)
+	gen.SetTheme(theme)
+	gen.SetChatEnabled(p.config.Ollama.Enabled)
+	if p.config.Output.BaseURL != "" {
+		gen.SetDocsBase(p.config.Output.BaseURL)
+	}
+	if p.config.Output.StaticPages {
+		gen.SetStaticIndex(strings.TrimSuffix(p.config.Output.BaseURL, "/") + "/pages/index.html")
+	}
 	if err := gen.Generate(doc); err != nil {
 		return fmt.Errorf("failed to generate HTML: %w", err)
 	}
 
+	// Generate static pages
+	if p.config.Output.StaticPages {
+		fmt.Println("→ Rendering static pages...")
+		pageGen := generator.NewPageGenerator(files, "templates/section.html", outputDir, p.config.Output.BaseURL, p.config.Output.PageLevel)
+		pageGen.SetTheme(theme)
+		pageGen.SetChatEnabled(p.config.Ollama.Enabled)
+		if err := pageGen.Generate(doc); err != nil {
+			return fmt.Errorf("failed to generate static pages: %w", err)
+		}
+	}
+
 	// Generate search index
 	fmt.Println("→ Creating search index...")
 	indexGen := search.NewIndexGenerator(outputDir)
@@ -180,41 +131,12 @@ func ProcessPDFDirect(pdfPath, outputDir string) error {
 	return nil
 }
 
-// extractImagesWithPdfimages uses the pdfimages command-line tool to extract images
-func extractImagesWithPdfimages(pdfPath, outputDir string) error {
-	// Check if pdfimages is available
-	if _, err := exec.LookPath("pdfimages"); err != nil {
-		return fmt.Errorf("pdfimages not found (install with: brew install poppler)")
-	}
-
-	// Create images directory
-	imageDir := filepath.Join(outputDir, "images")
-	if err := os.MkdirAll(imageDir, 0755); err != nil {
-		return fmt.Errorf("failed to create image directory: %w", err)
-	}
-
-	// Extract images as PNG
-	outputPrefix := filepath.Join(imageDir, "img")
-	cmd := exec.Command("pdfimages", "-png", pdfPath, outputPrefix)
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("pdfimages failed: %w\nOutput: %s", err, string(output))
-	}
-
-	// Count extracted images
-	entries, err := os.ReadDir(imageDir)
-	if err != nil {
-		return fmt.Errorf("failed to read image directory: %w", err)
-	}
-
-	imageCount := 0
-	for _, entry := range entries {
-		if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".png") || strings.HasSuffix(entry.Name(), ".jpg")) {
-			imageCount++
-		}
-	}
+// ProcessPDFDirect processes a PDF file directly (for CLI usage)
+func ProcessPDFDirect(pdfPath, outputDir string) error {
+	cfg := &config.Config{InputType: "pdf"}
+	cfg.PDF.Path = pdfPath
+	cfg.PDF.ExtractImages = true
+	cfg.Output.Directory = outputDir
 
-	fmt.Printf("  Extracted %d images\n", imageCount)
-	return nil
+	return New(cfg).Process()
 }
@@ -0,0 +1,137 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/source"
+)
+
+// sectionRefRegex matches references to section IDs in links and content
+var sectionRefRegex = regexp.MustCompile(`#section-(\d+)\b`)
+
+// parseSources reads every entry of the sources: list and merges the
+// results into one document. Each source is parsed with its own copy of the
+// configuration; its sections are renumbered and nested under its mount point.
+func (p *Processor) parseSources() (*document.Document, error) {
+	doc := &document.Document{
+		Title:    p.config.Output.Title,
+		Sections: make([]document.Section, 0),
+	}
+	images := make(map[string]int) // image name -> index in doc.Images
+	mounts := make(map[string]int) // mount path -> level of its section
+
+	for i, entry := range p.config.Sources {
+		fmt.Printf("\n=== Source %d: %s %s ===\n", i+1, entry.Type, entry.Path)
+
+		sourceCfg, err := p.sourceConfig(entry)
+		if err != nil {
+			return nil, fmt.Errorf("source %d: %w", i+1, err)
+		}
+
+		part, err := New(sourceCfg).parse()
+		if err != nil {
+			return nil, fmt.Errorf("source %d (%s): %w", i+1, entry.Path, err)
+		}
+
+		if doc.Title == "" {
+			doc.Title = part.Title
+		}
+		if doc.Language == "" {
+			doc.Language = part.Language
+		}
+
+		// Headings for the mount point, shared by sources with the same prefix
+		depth := 0
+		if entry.Mount != "" {
+			var mountPath []string
+			for _, name := range strings.Split(entry.Mount, "/") {
+				if name = strings.TrimSpace(name); name == "" {
+					continue
+				}
+				mountPath = append(mountPath, name)
+				key := strings.Join(mountPath, "/")
+				if _, exists := mounts[key]; !exists {
+					mounts[key] = len(mountPath)
+					doc.Sections = append(doc.Sections, document.Section{
+						ID:      fmt.Sprintf("section-%d", len(doc.Sections)+1),
+						Level:   len(mountPath),
+						Heading: name,
+						Images:  make([]document.Image, 0),
+					})
+				}
+			}
+			depth = len(mountPath)
+		}
+
+		// Renumber the sections after the ones already merged
+		ids := make(map[string]string)
+		for j, section := range part.Sections {
+			ids[section.ID] = fmt.Sprintf("section-%d", len(doc.Sections)+j+1)
+		}
+		for _, section := range part.Sections {
+			section.ID = ids[section.ID]
+			section.Content = renumber(section.Content, ids)
+			section.Markdown = renumber(section.Markdown, ids)
+			section.HTML = renumber(section.HTML, ids)
+			section.Level = min(section.Level+depth, 6)
+			doc.Sections = append(doc.Sections, section)
+		}
+
+		// Sources share the images directory; content-hash names that
+		// appear twice are the same file
+		for _, asset := range part.Images {
+			if idx, exists := images[asset.Name]; exists {
+				for _, original := range asset.Originals {
+					if !slices.Contains(doc.Images[idx].Originals, original) {
+						doc.Images[idx].Originals = append(doc.Images[idx].Originals, original)
+					}
+				}
+				continue
+			}
+			images[asset.Name] = len(doc.Images)
+			doc.Images = append(doc.Images, asset)
+		}
+	}
+
+	if doc.Title == "" {
+		doc.Title = "Documentation"
+	}
+
+	return doc, nil
+}
+
+// sourceConfig returns a copy of the configuration that reads one source
+func (p *Processor) sourceConfig(entry config.Source) (*config.Config, error) {
+	if entry.Path == "" {
+		return nil, fmt.Errorf("path not specified")
+	}
+	if _, err := os.Stat(entry.Path); err != nil {
+		return nil, fmt.Errorf("path not found: %s", entry.Path)
+	}
+
+	cfg := *p.config
+	cfg.Sources = nil
+	cfg.InputType = entry.Type
+	cfg.Output.Title = ""
+	if err := source.ApplyEntry(&cfg, entry); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// renumber rewrites "#section-N" references using ids
+func renumber(text string, ids map[string]string) string {
+	return sectionRefRegex.ReplaceAllStringFunc(text, func(match string) string {
+		if id, ok := ids[match[1:]]; ok {
+			return "#" + id
+		}
+		return match
+	})
+}
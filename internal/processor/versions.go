@@ -0,0 +1,276 @@
+package processor
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"docTrainerGO/internal/config"
+)
+
+// VersionManifest lists the documentation versions built into the output directory
+type VersionManifest struct {
+	Default  string         `json:"default"`
+	Versions []VersionEntry `json:"versions"`
+}
+
+// VersionEntry describes one built version
+type VersionEntry struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Path  string `json:"path"`
+}
+
+// processVersions builds every configured version into <output>/<version>/
+// and writes versions.json listing them
+func (p *Processor) processVersions() error {
+	outputDir := p.config.Output.Directory
+	manifest := VersionManifest{Versions: make([]VersionEntry, 0, len(p.config.Versions))}
+
+	for _, version := range p.config.Versions {
+		if err := validatePathSegment(version.Name); err != nil {
+			return err
+		}
+		fmt.Printf("\n=== Version %s ===\n", version.Name)
+
+		if err := p.buildVersion(version); err != nil {
+			return fmt.Errorf("version %s: %w", version.Name, err)
+		}
+
+		label := version.Label
+		if label == "" {
+			label = version.Name
+		}
+		manifest.Versions = append(manifest.Versions, VersionEntry{
+			Name:  version.Name,
+			Label: label,
+			Path:  "/v/" + version.Name + "/",
+		})
+		if version.Default || manifest.Default == "" {
+			manifest.Default = version.Name
+		}
+	}
+
+	// Write the version manifest
+	manifestPath := filepath.Join(outputDir, "versions.json")
+	if err := writeManifest(manifestPath, manifest); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nGenerated version manifest: %s (%d versions)\n", manifestPath, len(manifest.Versions))
+	return nil
+}
+
+// buildVersion builds one version into <output>/<version>/. A version built
+// from a git ref is exported to a temporary directory removed afterwards.
+func (p *Processor) buildVersion(version config.Version) error {
+	// Resolve the source directory, exporting the git ref if needed
+	sourceDir := version.Directory
+	if sourceDir == "" {
+		sourceDir = p.config.Markdown.Directory
+	}
+	if version.GitRef != "" {
+		exportDir, exportedDir, err := exportGitRef(version.GitRef, sourceDir)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(exportDir)
+		sourceDir = exportedDir
+	}
+
+	// Build the version with its own copy of the configuration
+	versionCfg := *p.config
+	versionCfg.Versions = nil
+	versionCfg.InputType = "markdown"
+	versionCfg.Markdown.Directory = sourceDir
+	versionCfg.Markdown.AutoDiscover = true
+	versionCfg.Output.Directory = filepath.Join(p.config.Output.Directory, version.Name)
+	versionCfg.Output.BaseURL = joinURL(p.config.Output.BaseURL, version.Name)
+
+	return New(&versionCfg).Process()
+}
+
+// validatePathSegment rejects version and locale names that are not safe
+// as a directory name and URL segment
+func validatePathSegment(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\?#%`) {
+		return fmt.Errorf("invalid version or locale name %q", name)
+	}
+	return nil
+}
+
+// reservedLocaleCodes are the top-level URL paths of the server, which a
+// locale served at /<code>/ would shadow
+var reservedLocaleCodes = map[string]bool{"v": true, "docs": true, "static": true, "api": true}
+
+// validateLocaleCode rejects locale codes that are not safe path segments or
+// clash with a server route
+func validateLocaleCode(code string) error {
+	if err := validatePathSegment(code); err != nil {
+		return err
+	}
+	if reservedLocaleCodes[code] {
+		return fmt.Errorf("locale code %q is reserved for a server route", code)
+	}
+	return nil
+}
+
+// joinURL appends a version or locale segment to the output base URL
+func joinURL(base, segment string) string {
+	return strings.TrimSuffix(base, "/") + "/" + url.PathEscape(segment)
+}
+
+// writeManifest writes a version or locale manifest as indented JSON
+func writeManifest(path string, manifest interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// exportGitRef extracts path at the given git ref into a temporary
+// directory. It returns the temporary directory and the exported path in it.
+func exportGitRef(ref, path string) (string, string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", "", fmt.Errorf("git not found (needed to build from git ref %s)", ref)
+	}
+
+	// git archive takes paths relative to the repository root
+	repoRoot, relPath, err := gitRelativePath(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "doctrainer-version-")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	fmt.Printf("→ Exporting %s from git ref %s\n", path, ref)
+	cmd := exec.Command("git", "archive", "--format=tar", ref, "--", filepath.ToSlash(relPath))
+	cmd.Dir = repoRoot
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return "", "", err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", "", fmt.Errorf("git archive failed: %w", err)
+	}
+	if err := extractTar(stdout, tmpDir); err != nil {
+		// Nothing reads the rest of the archive, so stop git before waiting
+		// for it; it would block on the full pipe otherwise
+		cmd.Process.Kill()
+		cmd.Wait()
+		os.RemoveAll(tmpDir)
+		return "", "", fmt.Errorf("failed to extract git archive: %w", err)
+	}
+
+	// Read the padding after the end of the archive so git can exit
+	io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", "", fmt.Errorf("git archive %s failed: %w\nOutput: %s", ref, err, stderr.String())
+	}
+
+	return tmpDir, filepath.Join(tmpDir, relPath), nil
+}
+
+// gitRelativePath returns the root of the git repository holding path and
+// path relative to it. The path may not exist in the working tree.
+func gitRelativePath(path string) (string, string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	// Start from the nearest directory that exists
+	dir, missing := absPath, ""
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("no existing directory above %s", path)
+		}
+		missing = filepath.Join(filepath.Base(dir), missing)
+		dir = parent
+	}
+
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("%s is not in a git repository: %w", path, err)
+	}
+	repoRoot := strings.TrimSpace(string(output))
+
+	// Compare resolved paths; the toplevel has its symlinks evaluated
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	relPath, err := filepath.Rel(repoRoot, realDir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to locate %s in %s: %w", path, repoRoot, err)
+	}
+	return repoRoot, filepath.Join(relPath, missing), nil
+}
+
+// extractTar writes the regular files and directories of a tar stream into dir
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("unsafe path in archive: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			file, err := os.Create(target)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(file, tr); err != nil {
+				file.Close()
+				return err
+			}
+			file.Close()
+		}
+	}
+}
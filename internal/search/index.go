@@ -5,8 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
-	"docTrainerGO/internal/pdf"
+	"docTrainerGO/internal/document"
 )
 
 // SearchIndex represents the search index structure for Fuse.js
@@ -20,6 +21,7 @@ type SearchItem struct {
 	Heading string `json:"heading"`
 	Content string `json:"content"`
 	Level   int    `json:"level"`
+	Tokens  string `json:"tokens"` // Normalized tokens of heading and full content
 }
 
 // IndexGenerator generates search indexes
@@ -35,15 +37,16 @@ func NewIndexGenerator(outputDir string) *IndexGenerator {
 }
 
 // Generate creates a JSON search index from the document
-func (ig *IndexGenerator) Generate(doc *pdf.Document) error {
+func (ig *IndexGenerator) Generate(doc *document.Document) error {
 	// Build search items from document sections
 	items := make([]SearchItem, 0, len(doc.Sections))
 
 	for _, section := range doc.Sections {
 		// Truncate content for search preview (first 200 chars)
-		content := section.Content
-		if len(content) > 200 {
-			content = content[:200] + "..."
+		fullContent := document.AdmonitionMarker.ReplaceAllString(section.Content, "$3")
+		content := fullContent
+		if runes := []rune(content); len(runes) > 200 {
+			content = string(runes[:200]) + "..."
 		}
 
 		items = append(items, SearchItem{
@@ -51,6 +54,7 @@ func (ig *IndexGenerator) Generate(doc *pdf.Document) error {
 			Heading: section.Heading,
 			Content: content,
 			Level:   section.Level,
+			Tokens:  strings.Join(UniqueTokens(section.Heading+" "+fullContent), " "),
 		})
 	}
 
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// letterFolds spells out letters that have no decomposition, matching the
+// replacements in script.js
+var letterFolds = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "œ", "oe", "þ", "th",
+	"ø", "o", "đ", "d", "ħ", "h", "ł", "l", "ı", "i", "ŧ", "t",
+)
+
+// Normalize lowercases text, folds compatibility forms such as full-width
+// letters and removes diacritics, both precomposed (é) and combining
+// (e + U+0301). It mirrors normalizeSearchText in script.js: NFKD, strip
+// the combining diacritical marks, then NFC.
+func Normalize(text string) string {
+	decomposed := norm.NFKD.String(text)
+	stripped := strings.Map(func(r rune) rune {
+		// Kana voicing marks are outside this block and are kept since
+		// they change the word
+		if r >= 0x0300 && r <= 0x036F {
+			return -1
+		}
+		return r
+	}, decomposed)
+	return letterFolds.Replace(strings.ToLower(norm.NFC.String(stripped)))
+}
+
+// isCJK reports whether r belongs to a script written without spaces. The
+// prolonged sound mark (ー) and iteration mark (々) are script-neutral but
+// only appear inside Japanese words.
+func isCJK(r rune) bool {
+	return r == 'ー' || r == '々' || unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Tokenize splits text into normalized search tokens. Words in space
+// separated scripts become one token each; runs of Chinese, Japanese or
+// Korean characters are split into overlapping bigrams ("東京都" -> "東京",
+// "京都") since they have no spaces to split on.
+func Tokenize(text string) []string {
+	tokens := make([]string, 0)
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			tokens = append(tokens, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range Normalize(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mc, r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	return tokens
+}
+
+// UniqueTokens returns the distinct tokens of text in order of first use
+func UniqueTokens(text string) []string {
+	seen := make(map[string]bool)
+	unique := make([]string, 0)
+	for _, token := range Tokenize(text) {
+		if !seen[token] {
+			seen[token] = true
+			unique = append(unique, token)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LocaleManifest represents docs/locales.json written in multi-language mode
+type LocaleManifest struct {
+	Default string `json:"default"`
+	Locales []struct {
+		Code string `json:"code"`
+		Name string `json:"name"`
+	} `json:"locales"`
+}
+
+// loadLocales reads locales.json if the docs were built in multi-language mode
+func (s *Server) loadLocales() error {
+	s.locales = make(map[string]string)
+	s.localeCodes = nil
+
+	file, err := os.Open(filepath.Join(s.docsDir, "locales.json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open locales.json: %w", err)
+	}
+	defer file.Close()
+
+	var manifest LocaleManifest
+	if err := json.NewDecoder(file).Decode(&manifest); err != nil {
+		return fmt.Errorf("failed to parse locales.json: %w", err)
+	}
+
+	for _, locale := range manifest.Locales {
+		s.locales[locale.Code] = locale.Name
+		s.localeCodes = append(s.localeCodes, locale.Code)
+	}
+	s.defaultLocale = manifest.Default
+	fmt.Printf("✓ Serving %d locales (default: %s)\n", len(s.locales), s.defaultLocale)
+	return nil
+}
+
+// negotiateLocale picks the best built locale for an Accept-Language header
+func (s *Server) negotiateLocale(header string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+
+	// Parse "de-CH, de;q=0.9, en;q=0.5" into tags ordered by quality
+	var candidates []candidate
+	rejected := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			// q=0 marks a language as not acceptable
+			rejected[strings.ToLower(tag)] = true
+			continue
+		}
+		candidates = append(candidates, candidate{tag: strings.ToLower(tag), q: q})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	// Exact match first, then the primary language subtag ("de-CH" -> "de").
+	// Locales are tried in the configured order, so the fallback between
+	// e.g. zh-CN and zh-TW always picks the one listed first.
+	for _, c := range candidates {
+		for _, code := range s.localeCodes {
+			if strings.ToLower(code) == c.tag {
+				return code
+			}
+		}
+		primary, _, _ := strings.Cut(c.tag, "-")
+		for _, code := range s.localeCodes {
+			codePrimary, _, _ := strings.Cut(strings.ToLower(code), "-")
+			if codePrimary == primary && !rejected[strings.ToLower(code)] {
+				return code
+			}
+		}
+	}
+
+	return s.defaultLocale
+}
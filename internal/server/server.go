@@ -3,6 +3,7 @@ package server
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
@@ -10,18 +11,35 @@ import (
 	"strings"
 
 	"docTrainerGO/internal/chat"
+	"docTrainerGO/internal/document"
 )
 
 // Server represents the HTTP server
 type Server struct {
-	port         string
-	docsDir      string
-	ollamaClient *chat.OllamaClient
+	port           string
+	docsDir        string
+	static         fs.FS // assets served under /static/
+	ollamaClient   *chat.OllamaClient
+	versions       map[string]bool // versions listed in versions.json
+	defaultVersion string
+	locales        map[string]string // locale code -> language name from locales.json
+	localeCodes    []string          // locale codes in the order of locales.json
+	defaultLocale  string
 }
 
 // ChatRequest represents the incoming chat request
 type ChatRequest struct {
-	Prompt string `json:"prompt"`
+	Prompt  string `json:"prompt"`
+	Version string `json:"version,omitempty"`
+	Locale  string `json:"locale,omitempty"`
+}
+
+// VersionManifest represents docs/versions.json written in multi-version mode
+type VersionManifest struct {
+	Default  string `json:"default"`
+	Versions []struct {
+		Name string `json:"name"`
+	} `json:"versions"`
 }
 
 // ChatResponse represents the chat response
@@ -42,18 +60,39 @@ type ContentData struct {
 
 // SectionData represents a section in content.json
 type SectionData struct {
-	ID      string   `json:"id"`
-	Level   int      `json:"level"`
-	Heading string   `json:"heading"`
-	Content string   `json:"content"`
-	Images  []string `json:"images"`
+	ID      string `json:"id"`
+	Level   int    `json:"level"`
+	Heading string `json:"heading"`
+	Content string `json:"content"`
+	Images  []struct {
+		Src   string `json:"src"`
+		Alt   string `json:"alt"`
+		Title string `json:"title"`
+	} `json:"images"`
+	Breadcrumbs []struct {
+		ID      string `json:"id"`
+		Heading string `json:"heading"`
+	} `json:"breadcrumbs"`
+}
+
+// Path returns the section's breadcrumb trail, e.g. "Configuration › Ollama › Models"
+func (sd SectionData) Path() string {
+	if len(sd.Breadcrumbs) == 0 {
+		return sd.Heading
+	}
+	headings := make([]string, len(sd.Breadcrumbs))
+	for i, crumb := range sd.Breadcrumbs {
+		headings[i] = crumb.Heading
+	}
+	return strings.Join(headings, " › ")
 }
 
 // New creates a new server instance
-func New(port, docsDir string, ollamaClient *chat.OllamaClient) *Server {
+func New(port, docsDir string, static fs.FS, ollamaClient *chat.OllamaClient) *Server {
 	return &Server{
 		port:         port,
 		docsDir:      docsDir,
+		static:       static,
 		ollamaClient: ollamaClient,
 	}
 }
@@ -61,17 +100,29 @@ func New(port, docsDir string, ollamaClient *chat.OllamaClient) *Server {
 // Start starts the HTTP server
 func (s *Server) Start() error {
 	// Serve static files
-	staticDir := "static"
-	fs := http.FileServer(http.Dir(staticDir))
-	http.Handle("/static/", http.StripPrefix("/static/", fs))
+	staticFS := http.FileServer(http.FS(s.static))
+	http.Handle("/static/", http.StripPrefix("/static/", staticFS))
 
 	// Serve docs directory
 	docsFS := http.FileServer(http.Dir(s.docsDir))
 	http.Handle("/docs/", http.StripPrefix("/docs/", docsFS))
 
+	// Load the version manifest (multi-version builds only)
+	if err := s.loadVersions(); err != nil {
+		return err
+	}
+
+	// Load the locale manifest (multi-language builds only)
+	if err := s.loadLocales(); err != nil {
+		return err
+	}
+
 	// Serve main page
 	http.HandleFunc("/", s.handleIndex)
 
+	// Serve versioned pages (/v/<version>/)
+	http.HandleFunc("/v/", s.handleVersionIndex)
+
 	// Chat API endpoint
 	http.HandleFunc("/api/chat", s.handleChat)
 
@@ -84,15 +135,78 @@ func (s *Server) Start() error {
 	return http.ListenAndServe(addr, nil)
 }
 
-// handleIndex serves the main page
+// handleIndex serves the main page, or the page of a locale at /<locale>/
 func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path == "/" {
+		if s.defaultVersion != "" {
+			http.Redirect(w, r, "/v/"+s.defaultVersion+"/", http.StatusFound)
+			return
+		}
+		if len(s.locales) > 0 {
+			locale := s.negotiateLocale(r.Header.Get("Accept-Language"))
+			w.Header().Set("Vary", "Accept-Language")
+			http.Redirect(w, r, "/"+locale+"/", http.StatusFound)
+			return
+		}
 		http.ServeFile(w, r, filepath.Join(s.docsDir, "index.html"))
 		return
 	}
+
+	locale, rest, hasSlash := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if _, ok := s.locales[locale]; ok {
+		if !hasSlash {
+			http.Redirect(w, r, "/"+locale+"/", http.StatusMovedPermanently)
+			return
+		}
+		if rest == "" {
+			http.ServeFile(w, r, filepath.Join(s.docsDir, locale, "index.html"))
+			return
+		}
+	}
 	http.NotFound(w, r)
 }
 
+// handleVersionIndex serves the main page of one documentation version
+func (s *Server) handleVersionIndex(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/v/")
+	version, _, hasSlash := strings.Cut(rest, "/")
+	if !s.versions[version] {
+		http.NotFound(w, r)
+		return
+	}
+	if !hasSlash {
+		http.Redirect(w, r, "/v/"+version+"/", http.StatusMovedPermanently)
+		return
+	}
+	http.ServeFile(w, r, filepath.Join(s.docsDir, version, "index.html"))
+}
+
+// loadVersions reads versions.json if the docs were built in multi-version mode
+func (s *Server) loadVersions() error {
+	s.versions = make(map[string]bool)
+
+	file, err := os.Open(filepath.Join(s.docsDir, "versions.json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open versions.json: %w", err)
+	}
+	defer file.Close()
+
+	var manifest VersionManifest
+	if err := json.NewDecoder(file).Decode(&manifest); err != nil {
+		return fmt.Errorf("failed to parse versions.json: %w", err)
+	}
+
+	for _, version := range manifest.Versions {
+		s.versions[version.Name] = true
+	}
+	s.defaultVersion = manifest.Default
+	fmt.Printf("✓ Serving %d documentation versions (default: %s)\n", len(s.versions), s.defaultVersion)
+	return nil
+}
+
 // handleChat processes chat requests from the frontend
 func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 	// Set CORS headers
@@ -130,15 +244,33 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Scope chat to the version the user is reading
+	if req.Version != "" && !s.versions[req.Version] {
+		s.respondWithError(w, "Unknown documentation version", http.StatusBadRequest)
+		return
+	}
+	if req.Version == "" {
+		req.Version = s.defaultVersion
+	}
+
+	// Scope chat to the locale the user is reading
+	if _, ok := s.locales[req.Locale]; req.Locale != "" && !ok {
+		s.respondWithError(w, "Unknown documentation locale", http.StatusBadRequest)
+		return
+	}
+	if req.Locale == "" {
+		req.Locale = s.defaultLocale
+	}
+
 	// Load documentation context from search index
-	context, err := s.loadDocumentationContext()
+	context, err := s.loadDocumentationContext(filepath.Join(req.Version, req.Locale))
 	if err != nil {
 		log.Printf("Warning: Could not load documentation context: %v", err)
 		context = "Documentation not available."
 	}
 
 	// Query Ollama with documentation context
-	answer, err := s.ollamaClient.AskWithContext(req.Prompt, context)
+	answer, err := s.ollamaClient.AskWithContextInLanguage(req.Prompt, context, s.locales[req.Locale])
 	if err != nil {
 		log.Printf("Ollama error: %v", err)
 		s.respondWithError(w, "Failed to get response from AI", http.StatusInternalServerError)
@@ -155,8 +287,9 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 }
 
 // loadDocumentationContext loads the documentation content from data/content.json
-func (s *Server) loadDocumentationContext() (string, error) {
-	contentPath := filepath.Join(s.docsDir, "data", "content.json")
+// in the given build subdirectory (a version or locale, empty for a single build)
+func (s *Server) loadDocumentationContext(buildDir string) (string, error) {
+	contentPath := filepath.Join(s.docsDir, buildDir, "data", "content.json")
 	file, err := os.Open(contentPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open content.json: %w", err)
@@ -175,8 +308,20 @@ func (s *Server) loadDocumentationContext() (string, error) {
 		content.Metadata.TotalSections, content.Metadata.TotalImages))
 
 	for _, section := range content.Sections {
-		contextBuilder.WriteString(fmt.Sprintf("## %s\n", section.Heading))
-		contextBuilder.WriteString(fmt.Sprintf("%s\n\n", section.Content))
+		contextBuilder.WriteString(fmt.Sprintf("## %s\n", section.Path()))
+		contextBuilder.WriteString(fmt.Sprintf("%s\n", markAdmonitions(section.Content)))
+
+		// Describe images so the model knows what they show
+		for _, image := range section.Images {
+			description := image.Alt
+			if image.Title != "" {
+				description = strings.TrimSpace(description + " — " + image.Title)
+			}
+			if description != "" {
+				contextBuilder.WriteString(fmt.Sprintf("[Image: %s]\n", description))
+			}
+		}
+		contextBuilder.WriteString("\n")
 	}
 
 	context := contextBuilder.String()
@@ -189,6 +334,19 @@ func (s *Server) loadDocumentationContext() (string, error) {
 	return context, nil
 }
 
+// markAdmonitions turns admonition markers into labelled text such as
+// "[WARNING] ..." so the model can recognise and prioritise them
+func markAdmonitions(content string) string {
+	return document.AdmonitionMarker.ReplaceAllStringFunc(content, func(match string) string {
+		parts := document.AdmonitionMarker.FindStringSubmatch(match)
+		label := strings.ToUpper(parts[1])
+		if parts[2] != "" {
+			label += ": " + parts[2]
+		}
+		return fmt.Sprintf("\n[%s] %s\n", label, parts[3])
+	})
+}
+
 // respondWithError sends an error response
 func (s *Server) respondWithError(w http.ResponseWriter, message string, statusCode int) {
 	w.WriteHeader(statusCode)
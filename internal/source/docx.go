@@ -0,0 +1,55 @@
+package source
+
+import (
+	"fmt"
+	"os"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/docx"
+)
+
+func init() {
+	Register("docx", func() Source { return &docxSource{} }, WithEntry(func(cfg *config.Config, entry config.Source) {
+		cfg.DOCX.Path = entry.Path
+	}))
+}
+
+// docxSource reads Word documents with docx.Parser
+type docxSource struct{}
+
+// Load parses the configured DOCX file or directory
+func (s *docxSource) Load(cfg *config.Config, outputDir string) (*document.Document, error) {
+	fmt.Println("Processing DOCX files...")
+
+	docxPath := cfg.DOCX.Path
+	if docxPath == "" {
+		return nil, fmt.Errorf("DOCX path not specified in config")
+	}
+
+	info, err := os.Stat(docxPath)
+	if err != nil {
+		return nil, fmt.Errorf("DOCX path not found: %s", docxPath)
+	}
+
+	parser := docx.NewParser(outputDir)
+
+	var doc *document.Document
+	if info.IsDir() {
+		fmt.Printf("→ Discovering files in: %s\n", docxPath)
+		doc, err = parser.ParseDirectory(docxPath)
+	} else {
+		fmt.Printf("→ Processing: %s\n", docxPath)
+		doc, err = parser.ParseFiles([]string{docxPath})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse docx: %w", err)
+	}
+
+	// Set title from config
+	if cfg.Output.Title != "" {
+		doc.Title = cfg.Output.Title
+	}
+
+	return doc, nil
+}
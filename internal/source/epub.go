@@ -0,0 +1,45 @@
+package source
+
+import (
+	"fmt"
+	"os"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/epub"
+)
+
+func init() {
+	Register("epub", func() Source { return &epubSource{} }, WithEntry(func(cfg *config.Config, entry config.Source) {
+		cfg.EPUB.Path = entry.Path
+	}))
+}
+
+// epubSource reads EPUB books with epub.Parser
+type epubSource struct{}
+
+// Load parses the configured EPUB file
+func (s *epubSource) Load(cfg *config.Config, outputDir string) (*document.Document, error) {
+	fmt.Println("Processing EPUB book...")
+
+	epubPath := cfg.EPUB.Path
+	if epubPath == "" {
+		return nil, fmt.Errorf("EPUB path not specified in config")
+	}
+	if _, err := os.Stat(epubPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("EPUB file not found: %s", epubPath)
+	}
+
+	fmt.Printf("→ Processing: %s\n", epubPath)
+	doc, err := epub.NewParser(outputDir).Parse(epubPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse epub: %w", err)
+	}
+
+	// Set title from config
+	if cfg.Output.Title != "" {
+		doc.Title = cfg.Output.Title
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,40 @@
+package source
+
+import (
+	"fmt"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/godoc"
+)
+
+func init() {
+	Register("godoc", func() Source { return &godocSource{} }, WithEntry(func(cfg *config.Config, entry config.Source) {
+		cfg.GoDoc.Directory = entry.Path
+	}))
+}
+
+// godocSource builds API reference sections from Go source with godoc.Parser
+type godocSource struct{}
+
+// Load documents the packages of the configured Go module
+func (s *godocSource) Load(cfg *config.Config, outputDir string) (*document.Document, error) {
+	fmt.Println("Processing Go packages...")
+
+	if cfg.GoDoc.Directory == "" {
+		return nil, fmt.Errorf("godoc directory not specified in config")
+	}
+
+	fmt.Printf("→ Reading module in: %s\n", cfg.GoDoc.Directory)
+	doc, err := godoc.NewParser().ParseModule(cfg.GoDoc.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go packages: %w", err)
+	}
+
+	// Set title from config
+	if cfg.Output.Title != "" {
+		doc.Title = cfg.Output.Title
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,46 @@
+package source
+
+import (
+	"fmt"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/htmldoc"
+)
+
+func init() {
+	Register("html", func() Source { return &htmlSource{} }, WithEntry(func(cfg *config.Config, entry config.Source) {
+		cfg.HTML.Directory = entry.Path
+		cfg.HTML.Selector = entry.Selector
+	}))
+}
+
+// htmlSource reads existing HTML pages with htmldoc.Parser
+type htmlSource struct{}
+
+// Load parses the configured directory of HTML pages
+func (s *htmlSource) Load(cfg *config.Config, outputDir string) (*document.Document, error) {
+	fmt.Println("Processing HTML files...")
+
+	if cfg.HTML.Directory == "" {
+		return nil, fmt.Errorf("HTML directory not specified in config")
+	}
+
+	parser, err := htmldoc.NewParser(outputDir, cfg.HTML.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid html.selector: %w", err)
+	}
+
+	fmt.Printf("→ Discovering files in: %s\n", cfg.HTML.Directory)
+	doc, err := parser.ParseDirectory(cfg.HTML.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html: %w", err)
+	}
+
+	// Set title from config
+	if cfg.Output.Title != "" {
+		doc.Title = cfg.Output.Title
+	}
+
+	return doc, nil
+}
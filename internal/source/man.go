@@ -0,0 +1,45 @@
+package source
+
+import (
+	"fmt"
+	"os"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/man"
+)
+
+func init() {
+	Register("man", func() Source { return &manSource{} }, WithEntry(func(cfg *config.Config, entry config.Source) {
+		cfg.Man.Path = entry.Path
+	}))
+}
+
+// manSource reads roff man pages with man.Parser
+type manSource struct{}
+
+// Load parses the configured man page or directory of man pages
+func (s *manSource) Load(cfg *config.Config, outputDir string) (*document.Document, error) {
+	fmt.Println("Processing man pages...")
+
+	manPath := cfg.Man.Path
+	if manPath == "" {
+		return nil, fmt.Errorf("man path not specified in config")
+	}
+	if _, err := os.Stat(manPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("man page path not found: %s", manPath)
+	}
+
+	fmt.Printf("→ Processing: %s\n", manPath)
+	doc, err := man.NewParser().ParsePath(manPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse man pages: %w", err)
+	}
+
+	// Set title from config
+	if cfg.Output.Title != "" {
+		doc.Title = cfg.Output.Title
+	}
+
+	return doc, nil
+}
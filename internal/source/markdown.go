@@ -0,0 +1,60 @@
+package source
+
+import (
+	"fmt"
+	"os"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/md"
+)
+
+func init() {
+	Register("markdown", func() Source { return &markdownSource{} }, WithEntry(func(cfg *config.Config, entry config.Source) {
+		// A directory is searched for pages, a file is read on its own
+		if info, err := os.Stat(entry.Path); err == nil && info.IsDir() {
+			cfg.Markdown.Directory = entry.Path
+			cfg.Markdown.AutoDiscover = true
+		} else {
+			cfg.Markdown.Files = []string{entry.Path}
+			cfg.Markdown.AutoDiscover = false
+		}
+	}))
+}
+
+// markdownSource reads Markdown files with md.Parser
+type markdownSource struct{}
+
+// Load parses the configured Markdown directory or file list
+func (s *markdownSource) Load(cfg *config.Config, outputDir string) (*document.Document, error) {
+	fmt.Println("Processing Markdown files...")
+
+	parser := md.NewParser(outputDir)
+	parser.SetVariables(cfg.Variables)
+	parser.SetProfile(cfg.Profile)
+	if cfg.Profile != "" {
+		fmt.Printf("→ Building profile: %s\n", cfg.Profile)
+	}
+
+	var doc *document.Document
+	var err error
+
+	if cfg.Markdown.AutoDiscover {
+		fmt.Printf("→ Auto-discovering files in: %s\n", cfg.Markdown.Directory)
+		doc, err = parser.ParseDirectory(cfg.Markdown.Directory)
+	} else {
+		fmt.Printf("→ Processing %d specified files\n", len(cfg.Markdown.Files))
+		doc, err = parser.ParseFiles(cfg.Markdown.Files)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse markdown: %w", err)
+	}
+
+	// Set title from config
+	if cfg.Output.Title != "" {
+		doc.Title = cfg.Output.Title
+	}
+
+	return doc, nil
+}
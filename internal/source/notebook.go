@@ -0,0 +1,55 @@
+package source
+
+import (
+	"fmt"
+	"os"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/notebook"
+)
+
+func init() {
+	Register("notebook", func() Source { return &notebookSource{} }, WithEntry(func(cfg *config.Config, entry config.Source) {
+		cfg.Notebook.Path = entry.Path
+	}))
+}
+
+// notebookSource reads Jupyter notebooks with notebook.Parser
+type notebookSource struct{}
+
+// Load parses the configured notebook or directory of notebooks
+func (s *notebookSource) Load(cfg *config.Config, outputDir string) (*document.Document, error) {
+	fmt.Println("Processing Jupyter notebooks...")
+
+	notebookPath := cfg.Notebook.Path
+	if notebookPath == "" {
+		return nil, fmt.Errorf("notebook path not specified in config")
+	}
+
+	info, err := os.Stat(notebookPath)
+	if err != nil {
+		return nil, fmt.Errorf("notebook path not found: %s", notebookPath)
+	}
+
+	parser := notebook.NewParser(outputDir)
+
+	var doc *document.Document
+	if info.IsDir() {
+		fmt.Printf("→ Discovering files in: %s\n", notebookPath)
+		doc, err = parser.ParseDirectory(notebookPath)
+	} else {
+		fmt.Printf("→ Processing: %s\n", notebookPath)
+		doc, err = parser.ParseFiles([]string{notebookPath})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse notebook: %w", err)
+	}
+
+	// Set title from config
+	if cfg.Output.Title != "" {
+		doc.Title = cfg.Output.Title
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,45 @@
+package source
+
+import (
+	"fmt"
+	"os"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/openapi"
+)
+
+func init() {
+	Register("openapi", func() Source { return &openAPISource{} }, WithEntry(func(cfg *config.Config, entry config.Source) {
+		cfg.OpenAPI.Path = entry.Path
+	}))
+}
+
+// openAPISource builds REST API reference sections with openapi.Parser
+type openAPISource struct{}
+
+// Load parses the configured OpenAPI specification
+func (s *openAPISource) Load(cfg *config.Config, outputDir string) (*document.Document, error) {
+	fmt.Println("Processing OpenAPI specification...")
+
+	specPath := cfg.OpenAPI.Path
+	if specPath == "" {
+		return nil, fmt.Errorf("OpenAPI path not specified in config")
+	}
+	if _, err := os.Stat(specPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("OpenAPI spec not found: %s", specPath)
+	}
+
+	fmt.Printf("→ Processing: %s\n", specPath)
+	doc, err := openapi.NewParser().Parse(specPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse openapi spec: %w", err)
+	}
+
+	// Set title from config
+	if cfg.Output.Title != "" {
+		doc.Title = cfg.Output.Title
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,102 @@
+package source
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+	"docTrainerGO/internal/pdf"
+)
+
+func init() {
+	Register("pdf", func() Source { return &pdfSource{} }, WithEntry(func(cfg *config.Config, entry config.Source) {
+		cfg.PDF.Path = entry.Path
+	}))
+}
+
+// pdfSource reads a PDF file with pdf.Parser
+type pdfSource struct{}
+
+// Load parses the configured PDF file
+func (s *pdfSource) Load(cfg *config.Config, outputDir string) (*document.Document, error) {
+	fmt.Println("Processing PDF file...")
+
+	pdfPath := cfg.PDF.Path
+	if pdfPath == "" {
+		return nil, fmt.Errorf("PDF path not specified in config")
+	}
+
+	fmt.Println("Processing PDF:", pdfPath)
+
+	// Check if PDF exists
+	if _, err := os.Stat(pdfPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("PDF file not found: %s", pdfPath)
+	}
+
+	parser := pdf.NewParser(outputDir)
+
+	// Extract images if enabled. pdfimages writes to a staging directory of
+	// this source, and only the files it extracted are attached.
+	if cfg.PDF.ExtractImages {
+		fmt.Println("→ Extracting images from PDF...")
+		stagingDir, err := os.MkdirTemp("", "doctrainer-pdf-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create staging directory: %w", err)
+		}
+		defer os.RemoveAll(stagingDir)
+
+		files, err := extractImagesWithPdfimages(pdfPath, stagingDir)
+		if err != nil {
+			fmt.Printf("  Warning: Image extraction failed: %v\n", err)
+			fmt.Println("  Continuing without images...")
+		}
+		parser.SetImageFiles(files)
+	}
+
+	// Parse PDF
+	fmt.Println("→ Parsing PDF and extracting content...")
+	doc, err := parser.Parse(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PDF: %w", err)
+	}
+
+	return doc, nil
+}
+
+// extractImagesWithPdfimages uses the pdfimages command-line tool to extract
+// images into stagingDir and returns the extracted files
+func extractImagesWithPdfimages(pdfPath, stagingDir string) ([]string, error) {
+	// Check if pdfimages is available
+	if _, err := exec.LookPath("pdfimages"); err != nil {
+		return nil, fmt.Errorf("pdfimages not found (install with: brew install poppler)")
+	}
+
+	// Extract images as PNG
+	outputPrefix := filepath.Join(stagingDir, "img")
+	cmd := exec.Command("pdfimages", "-png", pdfPath, outputPrefix)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("pdfimages failed: %w\nOutput: %s", err, string(output))
+	}
+
+	// Collect the extracted images in page order
+	entries, err := os.ReadDir(stagingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staging directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".png") || strings.HasSuffix(entry.Name(), ".jpg")) {
+			files = append(files, filepath.Join(stagingDir, entry.Name()))
+		}
+	}
+
+	fmt.Printf("  Extracted %d images\n", len(files))
+	return files, nil
+}
@@ -0,0 +1,90 @@
+package source
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"docTrainerGO/internal/config"
+	"docTrainerGO/internal/document"
+)
+
+// Source reads one input format into the format-neutral document model
+type Source interface {
+	// Load reads the input described by cfg. Images are written to
+	// <outputDir>/images and referenced by file name from the sections.
+	Load(cfg *config.Config, outputDir string) (*document.Document, error)
+}
+
+// Factory creates a new Source; every build gets its own instance so
+// sources can keep per-build state such as section counters
+type Factory func() Source
+
+// EntryFunc points a copy of the configuration at the input of one entry
+// of the sources: list, e.g. by setting cfg.PDF.Path to entry.Path
+type EntryFunc func(cfg *config.Config, entry config.Source)
+
+// Option configures how a source is registered
+type Option func(*registration)
+
+// WithEntry lets the source be listed under sources:, reading its input
+// from the entry through apply
+func WithEntry(apply EntryFunc) Option {
+	return func(r *registration) {
+		r.entry = apply
+	}
+}
+
+// registration is a registered source type
+type registration struct {
+	factory Factory
+	entry   EntryFunc
+}
+
+// registry maps an input_type value to its registration
+var registry = make(map[string]registration)
+
+// Register makes a source available under the given input type. It is
+// called from the init function of each source implementation.
+func Register(inputType string, factory Factory, options ...Option) {
+	if _, exists := registry[inputType]; exists {
+		panic("source: duplicate registration for input type " + inputType)
+	}
+	r := registration{factory: factory}
+	for _, option := range options {
+		option(&r)
+	}
+	registry[inputType] = r
+}
+
+// New returns a new source for the input type
+func New(inputType string) (Source, bool) {
+	r, ok := registry[inputType]
+	if !ok {
+		return nil, false
+	}
+	return r.factory(), true
+}
+
+// ApplyEntry points cfg at the input of an entry of the sources: list
+func ApplyEntry(cfg *config.Config, entry config.Source) error {
+	r, ok := registry[entry.Type]
+	if !ok {
+		return fmt.Errorf("unsupported source type: %q (must be one of: %s)", entry.Type, strings.Join(Types(), ", "))
+	}
+	if r.entry == nil {
+		return fmt.Errorf("source type %q cannot be used in sources", entry.Type)
+	}
+	r.entry(cfg, entry)
+	return nil
+}
+
+// Types returns the registered input types in sorted order
+func Types() []string {
+	types := make([]string, 0, len(registry))
+	for inputType := range registry {
+		types = append(types, inputType)
+	}
+	sort.Strings(types)
+	return types
+}
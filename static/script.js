@@ -5,16 +5,43 @@ let searchIndex = [];
 let fuse = null;
 let chatOpen = true;
 let contentData = null;
+let pageURLs = {};
+
+// Offline exports (-export-html) carry content, search index and images in
+// the page and are opened from file://
+const offlineData = window.offlineData || null;
+
+// Versioned builds are served at /v/<version>/ and localized builds at
+// /<locale>/, with their data under /docs/<version>/ or /docs/<locale>/
+const versionMatch = offlineData ? null : window.location.pathname.match(/^\/v\/([^/]+)\//);
+const localeMatch = offlineData ? null : window.location.pathname.match(/^\/(?!v\/|docs\/|static\/|api\/)([^/]+)\//);
+const currentVersion = versionMatch ? decodeURIComponent(versionMatch[1]) : '';
+const currentLocale = localeMatch ? decodeURIComponent(localeMatch[1]) : '';
+const buildDir = currentVersion || currentLocale;
+
+// Static pages (output.static_pages) are complete without JavaScript; the
+// script only adds search and chat, and they name their data directory
+const staticPage = document.body.hasAttribute('data-static-page');
+const docsBase = staticPage ? document.body.dataset.docsBase
+    : buildDir ? `/docs/${encodeURIComponent(buildDir)}` : '/docs';
 
 // ===========================
 // Initialization
 // ===========================
 document.addEventListener('DOMContentLoaded', () => {
-    loadContent();
+    if (staticPage) {
+        loadPageURLs();
+    } else {
+        loadContent();
+        initializeVersionSwitcher();
+        initializeLocaleSwitcher();
+    }
     initializeSidebar();
     initializeSearch();
     initializeChat();
-    initializeNavigation();
+    if (!staticPage) {
+        initializeNavigation();
+    }
 });
 
 // ===========================
@@ -22,11 +49,20 @@ document.addEventListener('DOMContentLoaded', () => {
 // ===========================
 async function loadContent() {
     try {
-        const response = await fetch('/docs/data/content.json');
-        contentData = await response.json();
+        if (offlineData) {
+            contentData = offlineData.content;
+        } else {
+            const response = await fetch(`${docsBase}/data/content.json`);
+            contentData = await response.json();
+        }
         
-        renderNavigation(contentData.sections);
+        if (contentData.toc && contentData.toc.length > 0) {
+            renderNavigationTree(contentData.toc);
+        } else {
+            renderNavigation(contentData.sections);
+        }
         renderContent(contentData.sections);
+        scrollToTranslatedSection();
         highlightActiveSection();
         
         console.log(`Loaded ${contentData.metadata.total_sections} sections`);
@@ -37,6 +73,18 @@ async function loadContent() {
     }
 }
 
+// Static pages open search results through the section -> page map
+async function loadPageURLs() {
+    try {
+        const response = await fetch(`${docsBase}/pages/pages.json`);
+        if (response.ok) {
+            pageURLs = await response.json();
+        }
+    } catch (error) {
+        console.error('Failed to load page map:', error);
+    }
+}
+
 function renderNavigation(sections) {
     const navMenu = document.getElementById('navMenu');
     navMenu.innerHTML = sections.map(section => `
@@ -46,6 +94,31 @@ function renderNavigation(sections) {
     `).join('');
 }
 
+function renderNavigationTree(toc) {
+    const navMenu = document.getElementById('navMenu');
+    navMenu.innerHTML = toc.map(node => renderNavNode(node)).join('');
+
+    // Collapse/expand chapters
+    navMenu.querySelectorAll('.nav-collapse').forEach(button => {
+        button.addEventListener('click', (e) => {
+            e.preventDefault();
+            e.stopPropagation();
+            button.parentElement.classList.toggle('collapsed');
+        });
+    });
+}
+
+function renderNavNode(node) {
+    const hasChildren = node.children && node.children.length > 0;
+    return `
+        <li class="nav-item nav-level-${node.level}${hasChildren ? ' has-children' : ''}">
+            ${hasChildren ? '<button class="nav-collapse" aria-label="Toggle section"></button>' : ''}
+            <a href="#${node.id}" class="nav-link">${escapeHtml(node.heading)}</a>
+            ${hasChildren ? `<ul class="nav-children">${node.children.map(child => renderNavNode(child)).join('')}</ul>` : ''}
+        </li>
+    `;
+}
+
 function renderContent(sections) {
     const contentContainer = document.getElementById('documentationContent');
     contentContainer.innerHTML = sections.map(section => `
@@ -53,22 +126,43 @@ function renderContent(sections) {
             <h${section.level} class="section-heading">${escapeHtml(section.heading)}</h${section.level}>
             
             <div class="section-content">
-                ${formatContentHTML(section.content)}
+                ${section.html ? resolveImagePaths(section.html) : formatContentHTML(section.content)}
             </div>
 
-            ${section.images && section.images.length > 0 ? `
-                <div class="section-images">
-                    ${section.images.map(img => `
-                        <div class="image-container">
-                            <img src="/docs/images/${img}" alt="${img}" loading="lazy">
-                        </div>
-                    `).join('')}
-                </div>
-            ` : ''}
+            ${renderDetachedImages(section.images)}
         </section>
     `).join('');
 }
 
+// Pre-rendered HTML references stored images as "images/..."
+function resolveImagePaths(html) {
+    return html.replace(/src="images\/([^"]+)"/g, (match, name) => `src="${imageURL(name)}"`);
+}
+
+// URL of a stored image; offline exports embed images as data URIs
+function imageURL(name) {
+    if (offlineData && offlineData.images[name]) {
+        return offlineData.images[name];
+    }
+    return `${docsBase}/images/${name}`;
+}
+
+// Images without an inline position (e.g. from PDFs) are listed after the section
+function renderDetachedImages(images) {
+    const detached = (images || []).filter(img => img.position < 0);
+    if (detached.length === 0) return '';
+    return `
+        <div class="section-images">
+            ${detached.map(img => `
+                <figure class="image-container">
+                    <img src="${imageURL(img.src)}" alt="${escapeHtml(img.alt || '')}" loading="lazy">
+                    ${img.title ? `<figcaption>${escapeHtml(img.title)}</figcaption>` : ''}
+                </figure>
+            `).join('')}
+        </div>
+    `;
+}
+
 function formatContentHTML(content) {
     if (!content) return '';
     
@@ -83,12 +177,22 @@ function formatContentHTML(content) {
     
     // Handle inline code (`...`)
     html = html.replace(/`([^`]+)`/g, '<code>$1</code>');
+
+    // Handle admonitions (:::type[title] body :::)
+    html = html.replace(/:::(\w+)(?:\[([^\]]*)\])?\s+([\s\S]*?)\s*:::/g, (match, type, title, body) => {
+        const label = title || type.charAt(0).toUpperCase() + type.slice(1);
+        return `<div class="admonition admonition-${type}" role="note"><p class="admonition-title">${escapeHtml(label)}</p><p>${body}</p></div>`;
+    });
     
     // Handle images ![alt](url) - convert to img tags
-    html = html.replace(/!\[([^\]]*)\]\(([^)]+)\)/g, (match, alt, url) => {
+    html = html.replace(/!\[([^\]]*)\]\(\s*([^)\s]+)(?:\s+"([^"]*)")?\s*\)/g, (match, alt, url, title) => {
         // Check if URL is relative (from images directory)
-        const imageSrc = url.startsWith('images/') ? `/docs/${url}` : url;
-        return `<img src="${imageSrc}" alt="${alt}" class="inline-image" loading="lazy">`;
+        const imageSrc = url.startsWith('images/') ? imageURL(url.substring('images/'.length)) : url;
+        const img = `<img src="${imageSrc}" alt="${escapeHtml(alt)}" class="inline-image" loading="lazy">`;
+        if (title) {
+            return `<figure class="inline-figure">${img}<figcaption>${escapeHtml(title)}</figcaption></figure>`;
+        }
+        return img;
     });
     
     // Handle bold (**text** or __text__)
@@ -99,8 +203,13 @@ function formatContentHTML(content) {
     html = html.replace(/\*([^*]+)\*/g, '<em>$1</em>');
     html = html.replace(/_([^_]+)_/g, '<em>$1</em>');
     
-    // Handle links [text](url)
-    html = html.replace(/\[([^\]]+)\]\(([^)]+)\)/g, '<a href="$2" target="_blank" rel="noopener">$1</a>');
+    // Handle links [text](url) - in-site section anchors stay in the page
+    html = html.replace(/\[([^\]]+)\]\(([^)]+)\)/g, (match, text, url) => {
+        if (url.startsWith('#')) {
+            return `<a href="${url}" class="section-link">${text}</a>`;
+        }
+        return `<a href="${url}" target="_blank" rel="noopener">${text}</a>`;
+    });
     
     // Handle bullet points (lines starting with -, *, or +)
     const lines = html.split('\n');
@@ -144,6 +253,89 @@ function formatContentHTML(content) {
     return processedLines.join('\n');
 }
 
+// ===========================
+// Version Switcher
+// ===========================
+async function initializeVersionSwitcher() {
+    if (!currentVersion) return;
+
+    try {
+        const response = await fetch('/docs/versions.json');
+        if (!response.ok) return;
+        const manifest = await response.json();
+
+        const select = document.createElement('select');
+        select.className = 'version-select';
+        select.setAttribute('aria-label', 'Documentation version');
+        select.innerHTML = manifest.versions.map(version => `
+            <option value="${escapeHtml(version.path)}" ${version.name === currentVersion ? 'selected' : ''}>
+                ${escapeHtml(version.label)}${version.name === manifest.default ? ' (latest)' : ''}
+            </option>
+        `).join('');
+
+        // Keep the reader's position when the section exists in the other version
+        select.addEventListener('change', () => {
+            window.location.href = select.value + window.location.hash;
+        });
+
+        document.querySelector('.sidebar-header').after(select);
+    } catch (error) {
+        console.error('Failed to load versions:', error);
+    }
+}
+
+// ===========================
+// Locale Switcher
+// ===========================
+async function initializeLocaleSwitcher() {
+    if (!currentLocale) return;
+
+    try {
+        const response = await fetch('/docs/locales.json');
+        if (!response.ok) return;
+        const manifest = await response.json();
+
+        const select = document.createElement('select');
+        select.className = 'version-select locale-select';
+        select.setAttribute('aria-label', 'Documentation language');
+        select.innerHTML = manifest.locales.map(locale => `
+            <option value="${escapeHtml(locale.path)}" ${locale.code === currentLocale ? 'selected' : ''}>
+                ${escapeHtml(locale.name)}
+            </option>
+        `).join('');
+
+        // Link to the same section through its canonical ID
+        select.addEventListener('change', () => {
+            window.location.href = select.value + currentCanonicalHash();
+        });
+
+        document.querySelector('.sidebar-header').after(select);
+    } catch (error) {
+        console.error('Failed to load locales:', error);
+    }
+}
+
+// Returns "#canonical:<id>" for the section currently being read
+function currentCanonicalHash() {
+    const active = document.querySelector('.nav-link.active');
+    const id = active ? active.getAttribute('href').substring(1) : window.location.hash.substring(1);
+    const section = contentData && contentData.sections.find(s => s.id === id);
+    return section && section.canonical_id ? `#canonical:${section.canonical_id}` : '';
+}
+
+// Resolves a "#canonical:<id>" link from another locale to this locale's section
+function scrollToTranslatedSection() {
+    const hash = window.location.hash.substring(1);
+    if (!hash.startsWith('canonical:')) return;
+
+    const canonicalId = hash.substring('canonical:'.length);
+    const section = contentData.sections.find(s => s.canonical_id === canonicalId);
+    if (section) {
+        history.replaceState(null, '', `#${section.id}`);
+        navigateToSection(section.id);
+    }
+}
+
 // ===========================
 // Sidebar Navigation
 // ===========================
@@ -171,29 +363,37 @@ function initializeSidebar() {
 // ===========================
 async function initializeSearch() {
     try {
-        // Try to load from new structured data format first
-        let response = await fetch('/docs/data/content.json');
-        if (response.ok) {
+        // The search index carries tokens normalized like the server-side
+        // tokenizer (accent folding, CJK bigrams)
+        let response = offlineData ? null : await fetch(`${docsBase}/search-index.json`);
+        if (offlineData) {
+            searchIndex = offlineData.search.items;
+        } else if (response.ok) {
+            const data = await response.json();
+            searchIndex = data.items;
+        } else {
+            // Fallback to structured data without tokens
+            response = await fetch(`${docsBase}/data/content.json`);
             const contentData = await response.json();
-            // Convert to search index format
             searchIndex = contentData.sections.map(section => ({
                 id: section.id,
                 heading: section.heading,
                 content: section.content,
-                level: section.level
+                level: section.level,
+                tokens: tokenizeSearchText(`${section.heading} ${section.content}`).join(' ')
             }));
-        } else {
-            // Fallback to old search-index.json
-            response = await fetch('/docs/search-index.json');
-            const data = await response.json();
-            searchIndex = data.items;
         }
 
         // Initialize Fuse.js
         const options = {
-            keys: ['heading', 'content'],
+            keys: [
+                { name: 'heading', weight: 2 },
+                { name: 'content', weight: 1 },
+                { name: 'tokens', weight: 1 }
+            ],
             threshold: 0.4,
             includeScore: true,
+            ignoreLocation: true,
             minMatchCharLength: 2
         };
         fuse = new Fuse(searchIndex, options);
@@ -228,7 +428,7 @@ async function initializeSearch() {
 
 function performSearch(query) {
     const searchResults = document.getElementById('searchResults');
-    const results = fuse.search(query);
+    const results = fuse.search(tokenizeSearchText(query).join(' ') || query);
 
     if (results.length === 0) {
         searchResults.innerHTML = '<div class="search-result-item"><div class="search-result-heading">No results found</div></div>';
@@ -253,6 +453,10 @@ function performSearch(query) {
 
 function navigateToSection(sectionId) {
     const section = document.getElementById(sectionId);
+    if (!section && pageURLs[sectionId]) {
+        window.location.href = pageURLs[sectionId];
+        return;
+    }
     if (section) {
         section.scrollIntoView({ behavior: 'smooth', block: 'start' });
         
@@ -274,6 +478,11 @@ function navigateToSection(sectionId) {
 // Chat Widget
 // ===========================
 async function initializeChat() {
+    // The widget is left out of the page when chat is disabled
+    if (!document.getElementById('chatWidget')) {
+        return;
+    }
+
     // Check if chat is enabled
     try {
         const response = await fetch('/api/chat', {
@@ -352,7 +561,7 @@ async function handleChatSubmit() {
             headers: {
                 'Content-Type': 'application/json',
             },
-            body: JSON.stringify({ prompt })
+            body: JSON.stringify({ prompt, version: currentVersion, locale: currentLocale })
         });
 
         const data = await response.json();
@@ -580,6 +789,37 @@ function highlightActiveSection() {
 // ===========================
 // Utility Functions
 // ===========================
+// Mirrors search.Tokenize: lowercase, fold width and accents, and split
+// Chinese/Japanese/Korean runs into bigrams
+function normalizeSearchText(text) {
+    return text
+        .normalize('NFKD')
+        .replace(/[\u0300-\u036f]/g, '')
+        .normalize('NFC')
+        .toLowerCase()
+        .replace(/ß/g, 'ss').replace(/æ/g, 'ae').replace(/œ/g, 'oe').replace(/þ/g, 'th')
+        .replace(/ø/g, 'o').replace(/đ/g, 'd').replace(/ħ/g, 'h').replace(/ł/g, 'l').replace(/ı/g, 'i').replace(/ŧ/g, 't');
+}
+
+function tokenizeSearchText(text) {
+    const tokens = [];
+    const runs = normalizeSearchText(text).match(/[\p{Script=Han}\p{Script=Hiragana}\p{Script=Katakana}\p{Script=Hangul}ー々]+|[\p{L}\p{N}\p{Mc}]+/gu) || [];
+    runs.forEach(run => {
+        const chars = Array.from(run);
+        if (/[\p{Script=Han}\p{Script=Hiragana}\p{Script=Katakana}\p{Script=Hangul}ー々]/u.test(chars[0])) {
+            if (chars.length === 1) {
+                tokens.push(run);
+            }
+            for (let i = 0; i + 1 < chars.length; i++) {
+                tokens.push(chars[i] + chars[i + 1]);
+            }
+        } else {
+            tokens.push(run);
+        }
+    });
+    return tokens;
+}
+
 function escapeHtml(text) {
     const div = document.createElement('div');
     div.textContent = text;